@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"gen-ai-example/pkg/agent"
@@ -20,51 +24,130 @@ func main() {
 		fmt.Println("  go run main.go chat --http             # 运行聊天模式示例 (HTTP导出器)")
 		fmt.Println("  go run main.go tool --http             # 运行工具调用模式示例 (HTTP导出器)")
 		fmt.Println("  go run main.go agent --http            # 运行Agent模式示例 (HTTP导出器)")
+		fmt.Println("  go run main.go chat --grpc             # 运行聊天模式示例 (gRPC导出器)")
+		fmt.Println("  go run main.go tool --grpc             # 运行工具调用模式示例 (gRPC导出器)")
+		fmt.Println("  go run main.go agent --grpc            # 运行Agent模式示例 (gRPC导出器)")
+		fmt.Println("  go run main.go agent --metrics-addr :9464  # 在 :9464/metrics 提供Prometheus指标")
+		fmt.Println("  go run main.go replay traces.jsonl     # 将OTLP-JSON文件回放到OTLP端点")
+		fmt.Println("  go run main.go replay traces.jsonl --rewrite-ids --shift-time  # 改写trace ID并将时间平移到当前")
+		fmt.Println("  go run main.go check traces.jsonl      # 检查OTLP-JSON文件中的span是否符合GenAI语义约定")
 		fmt.Println("")
 		fmt.Println("环境变量:")
 		fmt.Println("  OTEL_EXPORTER_OTLP_ENDPOINT            # OTLP端点 (默认: http://localhost:4318)")
+		fmt.Println("  OTEL_EXPORTER_OTLP_TRACES_ENDPOINT     # trace专用OTLP端点 (优先于通用端点，路径按原样使用)")
+		fmt.Println("  OTEL_EXPORTER_OTLP_PROTOCOL            # OTLP协议 (http/protobuf/grpc)，各信号的OTEL_EXPORTER_OTLP_{TRACES,METRICS,LOGS}_*设置优先")
+		fmt.Println("  OTEL_EXPORTER_OTLP_TIMEOUT             # 导出超时，单位毫秒")
+		fmt.Println("  OTEL_EXPORTER_OTLP_COMPRESSION         # 导出压缩方式 (gzip/none)")
+		fmt.Println("  OTEL_EXPORTER_FILE_PATH                # 文件导出器输出路径 (默认: traces.jsonl)")
+		fmt.Println("  OTEL_EXPORTER_FILE_MAX_BYTES           # 文件导出器轮转大小，单位字节 (默认: 100MB)")
+		fmt.Println("  OTEL_METRICS_EXPORTER                  # 指标导出器类型 (console/otlp/prometheus/none，默认仅console模式输出)")
+		fmt.Println("  OTEL_LOGS_EXPORTER                     # 日志导出器类型 (console/otlp/none，默认仅console模式输出事件)")
+		fmt.Println("  OTEL_INSTRUMENTATION_GENAI_EMIT_EVENTS # 设置为true时以日志事件记录GenAI消息内容")
+		fmt.Println("  OTEL_INSTRUMENTATION_GENAI_CAPTURE_MESSAGE_CONTENT # 消息内容采集策略 (off/metadata/full，默认off)")
+		fmt.Println("  OTEL_TRACES_SAMPLER                    # 采样器 (always_on/always_off/traceidratio/parentbased_*)")
+		fmt.Println("  OTEL_TRACES_SAMPLER_ARG                # 采样器参数，如采样比例 0.1")
+		fmt.Println("  OTEL_TRACES_KEEP_ERRORS                # 设置为true时始终保留包含失败span的trace")
+		fmt.Println("  OTEL_SEMCONV_STABILITY_OPT_IN          # GenAI语义约定版本 (默认最新版，gen_ai_latest_experimental/dup同时输出旧版)")
+		fmt.Println("  OTEL_INSTRUMENTATION_GENAI_VALIDATE_SEMCONV # 设置为true时在日志中报告不符合GenAI语义约定的span")
+		fmt.Println("  OTEL_INSTRUMENTATION_GENAI_OPENINFERENCE # 设置为true时额外导出OpenInference属性")
+		fmt.Println("  OTEL_ATTRIBUTE_VALUE_LENGTH_LIMIT      # span字符串属性的最大字符数，消息JSON截断后仍保持合法 (默认不限制)")
+		fmt.Println("  OTEL_SDK_DISABLED                      # 设置为true时禁用telemetry")
+		fmt.Println("  OTEL_PROPAGATORS                       # 跨进程传播格式 (tracecontext/baggage/b3/b3multi/none，默认tracecontext,baggage)")
 		fmt.Println("  OTEL_SERVICE_NAME                      # 服务名称 (默认: gen-ai-example)")
-		fmt.Println("  OTEL_TRACES_EXPORTER                   # 导出器类型 (console/http/otlp/auto)")
+		fmt.Println("  OTEL_TRACES_EXPORTER                   # 导出器类型 (console/http/grpc/otlp/file/auto/none)，可用逗号分隔多个")
+		fmt.Println("  GENAI_CHAT_PROVIDER                    # chat模式使用的模型后端 (mock/openai/azure/anthropic/ollama，默认mock)")
+		fmt.Println("  GENAI_CHAT_MODEL                       # chat模式请求的模型 (默认: gpt-3.5-turbo，anthropic为claude-3-5-haiku-latest，ollama为llama3.2)")
+		fmt.Println("  OPENAI_BASE_URL / OPENAI_API_KEY       # OpenAI兼容后端的地址 (默认: https://api.openai.com) 和API密钥")
+		fmt.Println("  AZURE_OPENAI_ENDPOINT / AZURE_OPENAI_API_KEY # Azure OpenAI部署地址和API密钥")
+		fmt.Println("  OPENAI_API_VERSION                     # Azure OpenAI的api-version")
+		fmt.Println("  ANTHROPIC_BASE_URL / ANTHROPIC_API_KEY # Anthropic后端的地址 (默认: https://api.anthropic.com) 和API密钥")
+		fmt.Println("  OLLAMA_HOST                            # Ollama服务地址 (默认: http://localhost:11434)")
+		fmt.Println("  GENAI_CHAT_STREAM                      # 设置为true时chat模式以流式方式输出回复")
 		return
 	}
 
-	// 检查是否使用HTTP导出器
+	// 检查是否使用HTTP/gRPC导出器、Prometheus监听地址以及回放选项
 	useHTTP := false
+	useGRPC := false
+	metricsAddr := ""
+	var replayOptions telemetry.ReplayOptions
 	mode := os.Args[1]
 
-	for i, arg := range os.Args {
-		if arg == "--http" {
+	args := os.Args[:0]
+	for i := 0; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		switch {
+		case arg == "--http":
 			useHTTP = true
-			// 移除--http参数
-			os.Args = append(os.Args[:i], os.Args[i+1:]...)
-			break
+		case arg == "--grpc":
+			useGRPC = true
+		case arg == "--metrics-addr" && i+1 < len(os.Args):
+			i++
+			metricsAddr = os.Args[i]
+		case strings.HasPrefix(arg, "--metrics-addr="):
+			metricsAddr = strings.TrimPrefix(arg, "--metrics-addr=")
+		case arg == "--rewrite-ids":
+			replayOptions.RewriteTraceIDs = true
+		case arg == "--shift-time":
+			replayOptions.ShiftToNow = true
+		default:
+			args = append(args, arg)
 		}
 	}
+	// 移除--http/--grpc/--metrics-addr及回放参数
+	os.Args = args
 
-	// 初始化telemetry
-	var cleanup func()
+	// 初始化telemetry，--grpc/--http只覆盖导出器类型，其余配置仍取自环境变量
+	config := telemetry.GetConfigFromEnv()
 
-	if useHTTP {
-		// 如果指定了--http，强制使用HTTP导出器
-		config := telemetry.Config{
-			ExporterType: telemetry.ExporterHTTP,
-			Endpoint:     "http://localhost:4318",
-			ServiceName:  "gen-ai-example",
+	switch {
+	case useGRPC:
+		// 如果指定了--grpc，强制使用gRPC导出器
+		config.ExporterType = telemetry.ExporterGRPC
+		config.Exporters = nil
+		if config.Endpoint == "" {
+			config.Endpoint = telemetry.DefaultGRPCEndpoint
 		}
-		cleanup = telemetry.InitTracerWithConfig(config)
-		fmt.Println("使用HTTP导出器，端点: http://localhost:4318")
-	} else {
-		// 否则从环境变量获取配置
-		config := telemetry.GetConfigFromEnv()
-		cleanup = telemetry.InitTracerWithConfig(config)
-
-		if config.ExporterType == telemetry.ExporterHTTP {
-			fmt.Printf("使用HTTP导出器，端点: %s\n", config.Endpoint)
-		} else {
-			fmt.Println("使用console导出器")
+	case useHTTP:
+		// 如果指定了--http，强制使用HTTP导出器
+		config.ExporterType = telemetry.ExporterHTTP
+		config.Exporters = nil
+		if config.Endpoint == "" {
+			config.Endpoint = telemetry.DefaultHTTPEndpoint
 		}
 	}
-	defer cleanup()
+
+	// 回放和检查模式直接读取文件中的span，不需要初始化provider
+	switch mode {
+	case "replay":
+		runReplay(config, replayOptions)
+		return
+	case "check":
+		runCheck()
+		return
+	}
+
+	printExporter(config)
+
+	if metricsAddr != "" {
+		config.PrometheusAddr = metricsAddr
+	}
+	if config.PrometheusAddr != "" {
+		fmt.Printf("Prometheus指标地址: http://%s/metrics\n", config.PrometheusAddr)
+	}
+
+	shutdown, err := telemetry.Setup(context.Background(), config)
+	if err != nil {
+		fmt.Printf("初始化telemetry失败: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), telemetry.DefaultShutdownTimeout)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			fmt.Printf("关闭telemetry失败: %v\n", err)
+		}
+	}()
 
 	// 运行相应的模式
 	switch mode {
@@ -81,4 +164,100 @@ func main() {
 
 	// 等待trace输出
 	time.Sleep(1 * time.Second)
+
+	// 提供Prometheus指标时保持运行，直到收到退出信号
+	if config.PrometheusAddr != "" {
+		fmt.Println("按 Ctrl+C 退出")
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		<-ctx.Done()
+	}
+}
+
+// printExporter 输出当前使用的导出器
+func printExporter(config telemetry.Config) {
+	endpoint := config.Endpoint
+	if config.TracesEndpoint != "" {
+		endpoint = config.TracesEndpoint
+	}
+
+	switch {
+	case config.Disabled:
+		fmt.Println("OTEL_SDK_DISABLED=true，已禁用telemetry")
+	case config.ExporterType == telemetry.ExporterNone && len(config.Exporters) == 0:
+		fmt.Println("OTEL_TRACES_EXPORTER=none，不导出trace")
+	case len(config.Exporters) > 1:
+		names := make([]string, 0, len(config.Exporters))
+		for _, spec := range config.Exporters {
+			names = append(names, fmt.Sprintf("%s(%s)", spec.Type, spec.Processor))
+		}
+		fmt.Printf("使用多个导出器: %s，OTLP端点: %s\n", strings.Join(names, ", "), endpoint)
+	case config.ExporterType == telemetry.ExporterHTTP:
+		fmt.Printf("使用HTTP导出器，端点: %s\n", endpoint)
+	case config.ExporterType == telemetry.ExporterGRPC:
+		fmt.Printf("使用gRPC导出器，端点: %s\n", endpoint)
+	case config.ExporterType == telemetry.ExporterAuto:
+		fmt.Printf("使用auto导出器，探测端点: %s\n", endpoint)
+	case config.ExporterType == telemetry.ExporterFile:
+		path := config.FilePath
+		if path == "" {
+			path = telemetry.DefaultFilePath
+		}
+		fmt.Printf("使用文件导出器，输出: %s\n", path)
+	default:
+		fmt.Println("使用console导出器")
+	}
+}
+
+// runReplay 将OTLP-JSON文件中的span重新发送到OTLP端点
+func runReplay(config telemetry.Config, opts telemetry.ReplayOptions) {
+	if len(os.Args) < 3 {
+		fmt.Println("使用方法: go run main.go replay <file> [--http|--grpc] [--rewrite-ids] [--shift-time]")
+		return
+	}
+	path := os.Args[2]
+
+	switch {
+	case config.ExporterType == telemetry.ExporterGRPC && config.Endpoint == "":
+		config.Endpoint = telemetry.DefaultGRPCEndpoint
+	case config.ExporterType != telemetry.ExporterGRPC:
+		config.ExporterType = telemetry.ExporterHTTP
+		if config.Endpoint == "" {
+			config.Endpoint = telemetry.DefaultHTTPEndpoint
+		}
+	}
+	fmt.Printf("=== 回放 %s ===\n", path)
+	printExporter(config)
+
+	sent, err := telemetry.Replay(context.Background(), config, path, opts)
+	if err != nil {
+		fmt.Printf("回放失败: %v\n", err)
+		return
+	}
+
+	fmt.Printf("已回放 %d 个span\n", sent)
+}
+
+// runCheck 检查OTLP-JSON文件中的span是否符合GenAI语义约定
+func runCheck() {
+	if len(os.Args) < 3 {
+		fmt.Println("使用方法: go run main.go check <file>")
+		return
+	}
+	path := os.Args[2]
+
+	violations, err := telemetry.CheckFile(path)
+	if err != nil {
+		fmt.Printf("检查失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, violation := range violations {
+		fmt.Println(violation)
+	}
+	if len(violations) > 0 {
+		fmt.Printf("发现 %d 处违反GenAI语义约定\n", len(violations))
+		os.Exit(1)
+	}
+	fmt.Println("所有span均符合GenAI语义约定")
 }
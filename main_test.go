@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gen-ai-example/pkg/agent"
+	"gen-ai-example/pkg/chat"
+	"gen-ai-example/pkg/tool"
+	"gen-ai-example/telemetry"
+)
+
+// TestModesConformToSemconv 以默认配置运行chat、tool和agent模式，导出的span应通过语义约定检查
+func TestModesConformToSemconv(t *testing.T) {
+	t.Setenv("GENAI_CHAT_PROVIDER", "")
+	t.Setenv("GENAI_CHAT_STREAM", "")
+
+	tests := []struct {
+		name    string
+		capture telemetry.ContentCapture
+	}{
+		{name: "default", capture: telemetry.ContentCaptureOff},
+		{name: "full content", capture: telemetry.ContentCaptureFull},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "traces.jsonl")
+			shutdown, err := telemetry.Setup(context.Background(), telemetry.Config{
+				ServiceName:    "conformance-test",
+				ExporterType:   telemetry.ExporterFile,
+				FilePath:       path,
+				ContentCapture: tt.capture,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			chat.RunChatMode()
+			tool.RunToolMode()
+			agent.RunAgentMode()
+
+			if err := shutdown(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+				t.Fatalf("no spans were exported to %s: %v", path, err)
+			}
+			violations, err := telemetry.CheckFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, violation := range violations {
+				t.Error(violation)
+			}
+		})
+	}
+}
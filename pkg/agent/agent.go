@@ -12,10 +12,14 @@ import (
 
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
+// planModel 模拟任务规划调用的模型名称
+const planModel = "gpt-3.5-turbo"
+
 type Task struct {
 	ID          string                 `json:"id"`
 	Description string                 `json:"description"`
@@ -26,18 +30,20 @@ type Task struct {
 }
 
 type Agent struct {
-	name   string
-	tracer trace.Tracer
-	tasks  []Task
-	tools  map[string]tool.Tool
+	name    string
+	tracer  trace.Tracer
+	metrics *telemetry.GenAIMetrics
+	tasks   []Task
+	tools   map[string]tool.Tool
 }
 
 func NewAgent(name string) *Agent {
 	return &Agent{
-		name:   name,
-		tracer: telemetry.GetTracer(fmt.Sprintf("agent-%s", name)),
-		tasks:  make([]Task, 0),
-		tools:  make(map[string]tool.Tool),
+		name:    name,
+		tracer:  telemetry.GetTracer(fmt.Sprintf("agent-%s", name)),
+		metrics: telemetry.NewGenAIMetrics(telemetry.GetMeter(fmt.Sprintf("agent-%s", name))),
+		tasks:   make([]Task, 0),
+		tools:   make(map[string]tool.Tool),
 	}
 }
 
@@ -79,16 +85,21 @@ func estimateOutputTokens(tasks []Task) int {
 }
 
 func (a *Agent) PlanTasks(ctx context.Context, objective string) error {
-	_, span := a.tracer.Start(ctx, "agent.plan_tasks",
+	start := time.Now()
+	// 任务规划是一次模型调用，按chat操作记录
+	ctx, span := a.tracer.Start(ctx, "chat "+planModel,
+		trace.WithSpanKind(trace.SpanKindClient),
 		trace.WithAttributes(
 			semconv.GenAIProviderNameOpenAI,
-			semconv.GenAIOperationNameCreateAgent,
+			semconv.GenAIOperationNameChat,
+			semconv.GenAIRequestModel(planModel),
 			semconv.GenAIAgentID(uuid.NewString()),
 			semconv.GenAIAgentName(a.name),
-			semconv.GenAIAgentDescription(objective),
 		),
 	)
 	defer span.End()
+	// 目标来自用户输入，按内容采集策略记录
+	span.SetAttributes(telemetry.ContentAttributes(semconv.GenAIAgentDescription(objective))...)
 
 	time.Sleep(200 * time.Millisecond)
 
@@ -138,27 +149,57 @@ func (a *Agent) PlanTasks(ctx context.Context, objective string) error {
 	inputTokens := estimateInputTokens(objective)
 	outputTokens := estimateOutputTokens(tasks)
 
+	// 输入输出消息写入span属性，或在启用GenAI事件时以日志事件发送
+	telemetry.RecordMessages(ctx, span,
+		[]attribute.KeyValue{
+			semconv.GenAIInputMessagesKey.String(fmt.Sprintf(`[{"role":"user","content":"%s"}]`, objective)),
+			semconv.GenAIOutputMessagesKey.String(outputMessages),
+		},
+		semconv.GenAIOperationNameChat,
+		semconv.GenAIProviderNameOpenAI,
+		semconv.GenAIRequestModel(planModel),
+		semconv.GenAIAgentName(a.name),
+	)
+
 	span.SetAttributes(
-		attribute.Int("gen_ai.agent.planned_tasks_count", len(tasks)),
-		semconv.GenAIInputMessagesKey.String(fmt.Sprintf(`[{"role":"user","content":"%s"}]`, objective)),
-		semconv.GenAIOutputMessagesKey.String(outputMessages),
+		attribute.Int("agent.planned_tasks_count", len(tasks)),
 		semconv.GenAIUsageInputTokens(inputTokens),
 		semconv.GenAIUsageOutputTokens(outputTokens),
 	)
 
+	metricAttrs := telemetry.GenAIMetricAttrs{
+		OperationName: semconv.GenAIOperationNameChat.Value.AsString(),
+		ProviderName:  semconv.GenAIProviderNameOpenAI.Value.AsString(),
+		RequestModel:  planModel,
+	}
+	a.metrics.RecordTokenUsage(ctx, metricAttrs, inputTokens, outputTokens)
+	a.metrics.RecordDuration(ctx, metricAttrs, time.Since(start), nil)
+
 	return nil
 }
 
-func (a *Agent) ExecuteTasks(ctx context.Context) error {
-	ctx, span := a.tracer.Start(ctx, "agent.execute_tasks",
+func (a *Agent) ExecuteTasks(ctx context.Context) (err error) {
+	start := time.Now()
+	ctx, span := a.tracer.Start(ctx, "invoke_agent "+a.name,
 		trace.WithAttributes(
 			semconv.GenAIProviderNameOpenAI,
 			semconv.GenAIOperationNameInvokeAgent,
 			semconv.GenAIAgentName(a.name),
-			attribute.Int("gen_id.agent.total_tasks", len(a.tasks)),
+			attribute.Int("agent.total_tasks", len(a.tasks)),
 		),
 	)
 	defer span.End()
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			span.SetAttributes(semconv.ErrorTypeOther)
+		}
+		a.metrics.RecordDuration(ctx, telemetry.GenAIMetricAttrs{
+			OperationName: semconv.GenAIOperationNameInvokeAgent.Value.AsString(),
+			ProviderName:  semconv.GenAIProviderNameOpenAI.Value.AsString(),
+		}, time.Since(start), err)
+	}()
 
 	var results []interface{}
 
@@ -168,15 +209,7 @@ func (a *Agent) ExecuteTasks(ctx context.Context) error {
 			continue
 		}
 
-		taskCtx, taskSpan := a.tracer.Start(ctx, fmt.Sprintf("agent.execute_task.%s", task.ID),
-			trace.WithAttributes(
-				attribute.String("gen_id.agent.task_id", task.ID),
-				attribute.String("gen_id.agent.task_type", task.Type),
-				attribute.String("gen_id.agent.description", task.Description),
-				semconv.GenAIToolName(fmt.Sprintf("task_%s", task.ID)),
-				semconv.GenAIToolDescription(task.Description),
-			),
-		)
+		taskCtx, taskSpan := a.startTaskSpan(ctx, task)
 
 		var err error
 		switch task.Type {
@@ -191,13 +224,15 @@ func (a *Agent) ExecuteTasks(ctx context.Context) error {
 		if err != nil {
 			task.Status = "failed"
 			taskSpan.RecordError(err)
+			taskSpan.SetStatus(codes.Error, err.Error())
+			taskSpan.SetAttributes(semconv.ErrorTypeOther)
 		} else {
 			task.Status = "completed"
 			results = append(results, task.Result)
 			resultJSON, _ := json.Marshal(task.Result)
-			taskSpan.SetAttributes(
-				attribute.String("gen_ai.task.result", string(resultJSON)),
-			)
+			taskSpan.SetAttributes(telemetry.ContentAttributes(
+				attribute.String("agent.task.result", string(resultJSON)),
+			)...)
 		}
 
 		taskSpan.End()
@@ -209,12 +244,47 @@ func (a *Agent) ExecuteTasks(ctx context.Context) error {
 
 	span.SetAttributes(
 		attribute.Int("agent.completed_tasks", len(results)),
-		semconv.GenAIOutputMessagesKey.String(fmt.Sprintf(`[{"role":"assistant","content":"任务执行完成，共完成%d个任务"}]`, len(results))),
+	)
+	telemetry.RecordMessages(ctx, span,
+		[]attribute.KeyValue{
+			semconv.GenAIOutputMessagesKey.String(fmt.Sprintf(`[{"role":"assistant","content":"任务执行完成，共完成%d个任务"}]`, len(results))),
+		},
+		semconv.GenAIOperationNameInvokeAgent,
+		semconv.GenAIProviderNameOpenAI,
+		semconv.GenAIAgentName(a.name),
 	)
 
 	return nil
 }
 
+// startTaskSpan 为任务创建span，工具调用任务按execute_tool操作记录
+func (a *Agent) startTaskSpan(ctx context.Context, task *Task) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("agent.task.id", task.ID),
+		attribute.String("agent.task.type", task.Type),
+	}
+	attrs = append(attrs, telemetry.ContentAttributes(attribute.String("agent.task.description", task.Description))...)
+
+	toolName, _ := task.Params["tool"].(string)
+	if task.Type != "tool_call" || toolName == "" {
+		return a.tracer.Start(ctx, fmt.Sprintf("agent.execute_task.%s", task.ID), trace.WithAttributes(attrs...))
+	}
+
+	attrs = append(attrs,
+		semconv.GenAIOperationNameExecuteTool,
+		semconv.GenAIToolName(toolName),
+		semconv.GenAIToolType("function"),
+		semconv.GenAIToolCallID(task.ID),
+	)
+	if tool, ok := a.tools[toolName]; ok {
+		attrs = append(attrs, semconv.GenAIToolDescription(tool.Description()))
+	}
+	return a.tracer.Start(ctx, "execute_tool "+toolName,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attrs...),
+	)
+}
+
 func (a *Agent) executeToolTask(ctx context.Context, task *Task) (interface{}, error) {
 	toolName, ok := task.Params["tool"].(string)
 	if !ok {
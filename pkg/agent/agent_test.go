@@ -0,0 +1,127 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"gen-ai-example/pkg/tool"
+	"gen-ai-example/telemetry"
+	"gen-ai-example/telemetry/telemetrytest"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTestAgent 创建注册了天气和计算器工具的Agent
+func newTestAgent() *Agent {
+	agent := NewAgent("assistant")
+	agent.RegisterTool(&tool.WeatherTool{})
+	agent.RegisterTool(&tool.CalculatorTool{})
+	return agent
+}
+
+func TestPlanTasks(t *testing.T) {
+	tests := []struct {
+		name      string
+		objective string
+		wantTasks []string
+	}{
+		{name: "weather and calculation", objective: "查询北京天气并计算10+25", wantTasks: []string{"task-1", "task-2", "task-final"}},
+		{name: "weather only", objective: "查询北京天气", wantTasks: []string{"task-1", "task-final"}},
+		{name: "summary only", objective: "写一首诗", wantTasks: []string{"task-final"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := telemetrytest.Install(t)
+			agent := newTestAgent()
+
+			ctx, root := telemetry.GetTracer("test").Start(context.Background(), "test.root")
+			err := agent.PlanTasks(ctx, tt.objective)
+			root.End()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tasks := agent.GetTaskResults()
+			if len(tasks) != len(tt.wantTasks) {
+				t.Fatalf("planned %d tasks, want %d", len(tasks), len(tt.wantTasks))
+			}
+			for i, id := range tt.wantTasks {
+				if tasks[i].ID != id {
+					t.Errorf("task %d = %s, want %s", i, tasks[i].ID, id)
+				}
+			}
+
+			span := recorder.Span(t, "chat "+planModel)
+			if span.SpanKind != trace.SpanKindClient {
+				t.Errorf("span kind = %s, want client", span.SpanKind)
+			}
+			telemetrytest.AssertParent(t, recorder.Span(t, "test.root"), span)
+			telemetrytest.AssertOperationName(t, span, "chat")
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIProviderNameOpenAI)
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIRequestModel(planModel))
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIAgentName("assistant"))
+			telemetrytest.AssertAttribute(t, span, attribute.Int("agent.planned_tasks_count", len(tt.wantTasks)))
+			telemetrytest.AssertHasTokenUsage(t, span)
+			telemetrytest.AssertConformance(t, span)
+		})
+	}
+}
+
+func TestExecuteTasks(t *testing.T) {
+	recorder := telemetrytest.Install(t)
+	agent := newTestAgent()
+
+	ctx, root := telemetry.GetTracer("test").Start(context.Background(), "test.root")
+	if err := agent.PlanTasks(ctx, "查询北京天气并计算10+25"); err != nil {
+		t.Fatal(err)
+	}
+	err := agent.ExecuteTasks(ctx)
+	root.End()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, task := range agent.GetTaskResults() {
+		if task.Status != "completed" {
+			t.Errorf("task %s status = %s, want completed", task.ID, task.Status)
+		}
+	}
+
+	rootSpan := recorder.Span(t, "test.root")
+	agentSpan := recorder.Span(t, "invoke_agent assistant")
+	telemetrytest.AssertParent(t, rootSpan, agentSpan)
+	telemetrytest.AssertOperationName(t, agentSpan, "invoke_agent")
+	telemetrytest.AssertAttribute(t, agentSpan, semconv.GenAIProviderNameOpenAI)
+	telemetrytest.AssertAttribute(t, agentSpan, semconv.GenAIAgentName("assistant"))
+	telemetrytest.AssertAttribute(t, agentSpan, attribute.Int("agent.completed_tasks", 3))
+
+	tests := []struct {
+		span string
+		tool string
+	}{
+		{span: "execute_tool get_weather", tool: "get_weather"},
+		{span: "execute_tool calculator", tool: "calculator"},
+		{span: "agent.execute_task.task-final"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.span, func(t *testing.T) {
+			span := recorder.Span(t, tt.span)
+			telemetrytest.AssertParent(t, agentSpan, span)
+			if tt.tool == "" {
+				telemetrytest.AssertNoAttribute(t, span, semconv.GenAIOperationNameKey)
+				return
+			}
+			if span.SpanKind != trace.SpanKindInternal {
+				t.Errorf("span kind = %s, want internal", span.SpanKind)
+			}
+			telemetrytest.AssertOperationName(t, span, "execute_tool")
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIToolName(tt.tool))
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIToolType("function"))
+		})
+	}
+
+	telemetrytest.AssertConformance(t, recorder.Spans()...)
+}
@@ -0,0 +1,263 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+const (
+	// DefaultAnthropicBaseURL Anthropic官方API地址
+	DefaultAnthropicBaseURL = "https://api.anthropic.com"
+	// anthropicVersion anthropic-version请求头
+	anthropicVersion = "2023-06-01"
+	// anthropicDefaultMaxTokens Messages API要求max_tokens，请求未指定时使用
+	anthropicDefaultMaxTokens = 1024
+)
+
+// AnthropicConfig Anthropic后端的配置
+type AnthropicConfig struct {
+	// BaseURL 服务地址，为空时使用DefaultAnthropicBaseURL
+	BaseURL string
+	// APIKey 以x-api-key请求头发送，为空时不发送（如经由自行添加认证的网关访问）
+	APIKey string
+	// HTTPClient 为nil时使用只限制连接超时的默认客户端，请求的整体期限由ctx控制
+	HTTPClient *http.Client
+}
+
+// AnthropicProvider 调用Anthropic的/v1/messages接口
+type AnthropicProvider struct {
+	endpoint string
+	apiKey   string
+	address  string
+	port     int
+	client   *http.Client
+}
+
+// NewAnthropicProvider 创建Anthropic后端
+func NewAnthropicProvider(config AnthropicConfig) (*AnthropicProvider, error) {
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultAnthropicBaseURL
+	}
+	u, port, err := parseServerURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// 兼容以/v1结尾的地址
+	path := strings.TrimSuffix(u.Path, "/")
+	if !strings.HasSuffix(path, "/v1") {
+		path += "/v1"
+	}
+	u.Path = path + "/messages"
+
+	return &AnthropicProvider{
+		endpoint: u.String(),
+		apiKey:   config.APIKey,
+		address:  u.Hostname(),
+		port:     port,
+		client:   httpClientOrDefault(config.HTTPClient),
+	}, nil
+}
+
+// Name 实现Provider接口
+func (p *AnthropicProvider) Name() string {
+	return semconv.GenAIProviderNameAnthropic.Value.AsString()
+}
+
+// ServerAddress 实现ServerAddresser接口
+func (p *AnthropicProvider) ServerAddress() (string, int) {
+	return p.address, p.port
+}
+
+// anthropicRequest /v1/messages的请求体
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float64            `json:"temperature,omitempty"`
+	TopP        float64            `json:"top_p,omitempty"`
+	Tools       []anthropicTool    `json:"tools,omitempty"`
+}
+
+// anthropicMessage Anthropic格式的消息，内容为content block数组
+type anthropicMessage struct {
+	Role    string           `json:"role"`
+	Content []anthropicBlock `json:"content"`
+}
+
+// anthropicBlock 一个content block，按Type使用不同字段
+type anthropicBlock struct {
+	Type string `json:"type"`
+	// text
+	Text string `json:"text,omitempty"`
+	// tool_use
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+	// tool_result
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+}
+
+// anthropicTool Anthropic格式的工具定义
+type anthropicTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+// anthropicResponse /v1/messages的响应体
+type anthropicResponse struct {
+	ID         string           `json:"id"`
+	Model      string           `json:"model"`
+	Role       string           `json:"role"`
+	Content    []anthropicBlock `json:"content"`
+	StopReason string           `json:"stop_reason"`
+	Usage      struct {
+		InputTokens              int `json:"input_tokens"`
+		OutputTokens             int `json:"output_tokens"`
+		CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+		CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	} `json:"usage"`
+}
+
+// anthropicErrorMessage 从错误响应体中提取错误信息
+func anthropicErrorMessage(body []byte) string {
+	var apiErr struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &apiErr) != nil || apiErr.Error.Message == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s: %s", apiErr.Error.Type, apiErr.Error.Message)
+}
+
+// toAnthropicMessages 将消息转换为Anthropic格式
+//
+// system消息合并为单独的system字段；tool消息转换为user消息中的tool_result block；
+// 相邻的同角色消息合并为一条，以满足user/assistant交替的要求。
+func toAnthropicMessages(messages []Message) (string, []anthropicMessage) {
+	var system []string
+	var result []anthropicMessage
+	for _, m := range messages {
+		role := m.Role
+		var blocks []anthropicBlock
+		switch m.Role {
+		case "system":
+			system = append(system, m.Content)
+			continue
+		case "tool":
+			role = "user"
+			blocks = append(blocks, anthropicBlock{Type: "tool_result", ToolUseID: m.ToolCallID, Content: m.Content})
+		default:
+			if m.Content != "" {
+				blocks = append(blocks, anthropicBlock{Type: "text", Text: m.Content})
+			}
+			for _, call := range m.ToolCalls {
+				input := call.Arguments
+				if len(input) == 0 {
+					input = json.RawMessage("{}")
+				}
+				blocks = append(blocks, anthropicBlock{Type: "tool_use", ID: call.ID, Name: call.Name, Input: input})
+			}
+		}
+
+		if n := len(result); n > 0 && result[n-1].Role == role {
+			result[n-1].Content = append(result[n-1].Content, blocks...)
+			continue
+		}
+		result = append(result, anthropicMessage{Role: role, Content: blocks})
+	}
+	return strings.Join(system, "\n"), result
+}
+
+// fromAnthropicBlocks 将响应的content block转换为Message
+func fromAnthropicBlocks(role string, blocks []anthropicBlock) Message {
+	message := Message{Role: role}
+	var texts []string
+	for _, block := range blocks {
+		switch block.Type {
+		case "text":
+			texts = append(texts, block.Text)
+		case "tool_use":
+			message.ToolCalls = append(message.ToolCalls, ToolCall{ID: block.ID, Name: block.Name, Arguments: block.Input})
+		}
+	}
+	message.Content = strings.Join(texts, "")
+	return message
+}
+
+// AdaptRequest 实现RequestAdapter接口
+//
+// Messages API不支持seed和frequency/presence penalty，这些参数不发送也不记录；
+// max_tokens为必填参数，未指定时使用anthropicDefaultMaxTokens。
+func (p *AnthropicProvider) AdaptRequest(req Request) Request {
+	req.Seed = 0
+	req.FrequencyPenalty = 0
+	req.PresencePenalty = 0
+	if req.MaxTokens == 0 {
+		req.MaxTokens = anthropicDefaultMaxTokens
+	}
+	return req
+}
+
+// Chat 实现Provider接口
+func (p *AnthropicProvider) Chat(ctx context.Context, req Request) (Response, error) {
+	req = p.AdaptRequest(req)
+	system, messages := toAnthropicMessages(req.Messages)
+	body := anthropicRequest{
+		Model:       req.Model,
+		System:      system,
+		Messages:    messages,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+	}
+	for _, tool := range req.Tools {
+		schema := tool.Parameters
+		if len(schema) == 0 {
+			schema = json.RawMessage(`{"type":"object"}`)
+		}
+		body.Tools = append(body.Tools, anthropicTool{Name: tool.Name, Description: tool.Description, InputSchema: schema})
+	}
+
+	header := http.Header{}
+	if p.apiKey != "" {
+		header.Set("x-api-key", p.apiKey)
+	}
+	header.Set("anthropic-version", anthropicVersion)
+	httpResp, err := postJSON(ctx, p.client, p.endpoint, header, body, anthropicErrorMessage)
+	if err != nil {
+		return Response{}, fmt.Errorf("anthropic messages request failed: %w", err)
+	}
+
+	var result anthropicResponse
+	if err := decodeJSON(httpResp, &result); err != nil {
+		return Response{}, err
+	}
+
+	// input_tokens不含缓存部分，按语义约定计入输入tokens
+	usage := result.Usage
+	return Response{
+		ID:           result.ID,
+		Model:        result.Model,
+		Message:      fromAnthropicBlocks(result.Role, result.Content),
+		FinishReason: result.StopReason,
+		Usage: Usage{
+			InputTokens:              usage.InputTokens + usage.CacheReadInputTokens + usage.CacheCreationInputTokens,
+			OutputTokens:             usage.OutputTokens,
+			CacheReadInputTokens:     usage.CacheReadInputTokens,
+			CacheCreationInputTokens: usage.CacheCreationInputTokens,
+		},
+	}, nil
+}
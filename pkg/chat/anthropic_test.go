@@ -0,0 +1,234 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"gen-ai-example/telemetry/telemetrytest"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+func TestAnthropicProvider(t *testing.T) {
+	const response = `{
+		"id": "msg_123",
+		"model": "claude-3-5-haiku-20241022",
+		"role": "assistant",
+		"content": [{"type": "text", "text": "你好！"}],
+		"stop_reason": "end_turn",
+		"usage": {"input_tokens": 20, "output_tokens": 6, "cache_read_input_tokens": 100, "cache_creation_input_tokens": 50}
+	}`
+
+	tests := []struct {
+		name          string
+		apiKey        string
+		maxTokens     int
+		wantMaxTokens int
+	}{
+		{name: "with API key", apiKey: "sk-ant-test", maxTokens: 512, wantMaxTokens: 512},
+		{name: "without API key uses default max tokens", wantMaxTokens: anthropicDefaultMaxTokens},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := telemetrytest.Install(t)
+			server, received := newTestServer(t, http.StatusOK, response)
+
+			provider, err := NewAnthropicProvider(AnthropicConfig{BaseURL: server.URL, APIKey: tt.apiKey})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cs := NewChatServiceWithProvider(provider, defaultAnthropicModel)
+
+			resp, err := cs.Chat(context.Background(), Request{
+				Model: defaultAnthropicModel,
+				Messages: []Message{
+					{Role: "system", Content: "你是一个助手"},
+					{Role: "user", Content: "你好"},
+				},
+				MaxTokens:        tt.maxTokens,
+				Temperature:      0.7,
+				FrequencyPenalty: 0.5,
+				PresencePenalty:  0.5,
+				Seed:             42,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Message.Content != "你好！" {
+				t.Errorf("content = %q, want 你好！", resp.Message.Content)
+			}
+
+			// 请求映射
+			if received.path != "/v1/messages" {
+				t.Errorf("path = %s, want /v1/messages", received.path)
+			}
+			if got := received.header.Get("anthropic-version"); got != anthropicVersion {
+				t.Errorf("anthropic-version = %q, want %s", got, anthropicVersion)
+			}
+			if _, ok := received.header["X-Api-Key"]; ok != (tt.apiKey != "") {
+				t.Errorf("x-api-key present = %v, want %v", ok, tt.apiKey != "")
+			} else if ok && received.header.Get("x-api-key") != tt.apiKey {
+				t.Errorf("x-api-key = %q, want %s", received.header.Get("x-api-key"), tt.apiKey)
+			}
+			if received.body["system"] != "你是一个助手" {
+				t.Errorf("request system = %v", received.body["system"])
+			}
+			if got := received.body["max_tokens"]; got != float64(tt.wantMaxTokens) {
+				t.Errorf("request max_tokens = %v, want %d", got, tt.wantMaxTokens)
+			}
+			for _, key := range []string{"seed", "frequency_penalty", "presence_penalty"} {
+				if _, ok := received.body[key]; ok {
+					t.Errorf("request has unsupported parameter %s", key)
+				}
+			}
+
+			// 只记录实际发送的请求参数
+			address, port := serverAddress(t, server)
+			span := recorder.Span(t, "chat "+defaultAnthropicModel)
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIProviderNameAnthropic)
+			telemetrytest.AssertAttribute(t, span, semconv.ServerAddress(address))
+			telemetrytest.AssertAttribute(t, span, semconv.ServerPort(port))
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIRequestMaxTokens(tt.wantMaxTokens))
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIRequestTemperature(0.7))
+			telemetrytest.AssertNoAttribute(t, span, semconv.GenAIRequestSeedKey)
+			telemetrytest.AssertNoAttribute(t, span, semconv.GenAIRequestFrequencyPenaltyKey)
+			telemetrytest.AssertNoAttribute(t, span, semconv.GenAIRequestPresencePenaltyKey)
+
+			// 响应解析，输入tokens包含缓存部分
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIResponseID("msg_123"))
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIResponseModel("claude-3-5-haiku-20241022"))
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIResponseFinishReasons("end_turn"))
+			telemetrytest.AssertTokenUsage(t, span, 170, 6)
+			telemetrytest.AssertAttribute(t, span, attribute.Int("gen_ai.usage.cache_read.input_tokens", 100))
+			telemetrytest.AssertAttribute(t, span, attribute.Int("gen_ai.usage.cache_creation.input_tokens", 50))
+			telemetrytest.AssertConformance(t, span)
+		})
+	}
+}
+
+func TestAnthropicProviderError(t *testing.T) {
+	recorder := telemetrytest.Install(t)
+	server, _ := newTestServer(t, http.StatusTooManyRequests,
+		`{"type":"error","error":{"type":"rate_limit_error","message":"Number of requests has exceeded your rate limit"}}`)
+
+	provider, err := NewAnthropicProvider(AnthropicConfig{BaseURL: server.URL, APIKey: "sk-ant-test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := NewChatServiceWithProvider(provider, defaultAnthropicModel)
+
+	const want = "anthropic messages request failed: request failed with status 429: rate_limit_error: Number of requests has exceeded your rate limit"
+	_, err = cs.Chat(context.Background(), Request{
+		Model:    defaultAnthropicModel,
+		Messages: []Message{{Role: "user", Content: "你好"}},
+	})
+	if err == nil || err.Error() != want {
+		t.Fatalf("Chat() error = %v, want %s", err, want)
+	}
+
+	span := recorder.Span(t, "chat "+defaultAnthropicModel)
+	if span.Status.Code != codes.Error {
+		t.Errorf("status = %s, want error", span.Status.Code)
+	}
+	telemetrytest.AssertAttribute(t, span, semconv.ErrorTypeOther)
+	telemetrytest.AssertConformance(t, span)
+}
+
+func TestAnthropicProviderToolUse(t *testing.T) {
+	const response = `{
+		"id": "msg_456",
+		"model": "claude-3-5-haiku-20241022",
+		"role": "assistant",
+		"content": [
+			{"type": "text", "text": "北京晴。"},
+			{"type": "text", "text": "再查一下上海。"},
+			{"type": "tool_use", "id": "toolu_3", "name": "get_weather", "input": {"city": "上海"}}
+		],
+		"stop_reason": "tool_use",
+		"usage": {"input_tokens": 80, "output_tokens": 30}
+	}`
+
+	telemetrytest.Install(t)
+	server, received := newTestServer(t, http.StatusOK, response)
+
+	provider, err := NewAnthropicProvider(AnthropicConfig{BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := NewChatServiceWithProvider(provider, defaultAnthropicModel)
+
+	resp, err := cs.Chat(context.Background(), Request{
+		Model: defaultAnthropicModel,
+		Messages: []Message{
+			{Role: "user", Content: "北京和上海天气怎么样"},
+			{Role: "assistant", Content: "我来查询。", ToolCalls: []ToolCall{
+				{ID: "toolu_1", Name: "get_weather", Arguments: json.RawMessage(`{"city":"北京"}`)},
+				{ID: "toolu_2", Name: "get_time"},
+			}},
+			{Role: "tool", ToolCallID: "toolu_1", Content: `{"weather":"晴"}`},
+			{Role: "tool", ToolCallID: "toolu_2", Content: "12:00"},
+		},
+		Tools: []ToolDefinition{
+			{Name: "get_weather", Description: "查询天气", Parameters: json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}}}`)},
+			{Name: "get_time"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 请求中的tool_use和tool_result block，相邻的tool结果合并为一条user消息
+	wantMessages := `[
+		{"role":"user","content":[{"type":"text","text":"北京和上海天气怎么样"}]},
+		{"role":"assistant","content":[
+			{"type":"text","text":"我来查询。"},
+			{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{"city":"北京"}},
+			{"type":"tool_use","id":"toolu_2","name":"get_time","input":{}}
+		]},
+		{"role":"user","content":[
+			{"type":"tool_result","tool_use_id":"toolu_1","content":"{\"weather\":\"晴\"}"},
+			{"type":"tool_result","tool_use_id":"toolu_2","content":"12:00"}
+		]}
+	]`
+	assertJSONEqual(t, "messages", received.body["messages"], wantMessages)
+	wantTools := `[
+		{"name":"get_weather","description":"查询天气","input_schema":{"type":"object","properties":{"city":{"type":"string"}}}},
+		{"name":"get_time","input_schema":{"type":"object"}}
+	]`
+	assertJSONEqual(t, "tools", received.body["tools"], wantTools)
+
+	// 响应中的文本和tool_use block
+	if resp.Message.Role != "assistant" || resp.Message.Content != "北京晴。再查一下上海。" {
+		t.Errorf("message = %+v", resp.Message)
+	}
+	if len(resp.Message.ToolCalls) != 1 {
+		t.Fatalf("tool calls = %+v, want 1", resp.Message.ToolCalls)
+	}
+	call := resp.Message.ToolCalls[0]
+	if call.ID != "toolu_3" || call.Name != "get_weather" || string(call.Arguments) != `{"city": "上海"}` {
+		t.Errorf("tool call = %+v", call)
+	}
+	if resp.FinishReason != "tool_use" {
+		t.Errorf("finish reason = %s, want tool_use", resp.FinishReason)
+	}
+}
+
+// assertJSONEqual 断言解码后的请求字段与want的JSON等价
+func assertJSONEqual(t *testing.T, name string, got interface{}, want string) {
+	t.Helper()
+
+	var wantValue interface{}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, wantValue) {
+		gotJSON, _ := json.Marshal(got)
+		t.Errorf("request %s = %s, want %s", name, gotJSON, want)
+	}
+}
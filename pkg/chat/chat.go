@@ -2,7 +2,9 @@ package chat
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -10,34 +12,20 @@ import (
 
 	"gen-ai-example/telemetry"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
-// contains 检查字符串是否包含子字符串（不区分大小写）
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr ||
-		len(s) > len(substr) && (containsIgnoreCase(s, substr) ||
-			containsWord(s, substr)))
-}
+// chatModel 默认调用的模型名称
+const chatModel = "gpt-3.5-turbo"
 
-// containsIgnoreCase 不区分大小写包含检查
-func containsIgnoreCase(s, substr string) bool {
-	s = strings.ToLower(s)
-	substr = strings.ToLower(substr)
-	return strings.Contains(s, substr)
-}
+// defaultAnthropicModel 使用Anthropic后端时默认调用的模型名称
+const defaultAnthropicModel = "claude-3-5-haiku-latest"
 
-// containsWord 检查是否包含完整单词
-func containsWord(s, word string) bool {
-	words := strings.Fields(s)
-	for _, w := range words {
-		if strings.Contains(strings.ToLower(w), strings.ToLower(word)) {
-			return true
-		}
-	}
-	return false
-}
+// defaultOllamaModel 使用Ollama后端时默认调用的模型名称
+const defaultOllamaModel = "llama3.2"
 
 type ChatRequest struct {
 	Message string `json:"message"`
@@ -50,82 +38,295 @@ type ChatResponse struct {
 }
 
 type ChatService struct {
-	tracer trace.Tracer
+	provider Provider
+	model    string
+	tracer   trace.Tracer
+	metrics  *telemetry.GenAIMetrics
 }
 
+// NewChatService 创建使用MockProvider的聊天服务
 func NewChatService() *ChatService {
+	return NewChatServiceWithProvider(&MockProvider{}, chatModel)
+}
+
+// NewChatServiceWithProvider 创建使用指定后端和模型的聊天服务
+func NewChatServiceWithProvider(provider Provider, model string) *ChatService {
 	return &ChatService{
-		tracer: telemetry.GetTracer("chat-service"),
+		provider: provider,
+		model:    model,
+		tracer:   telemetry.GetTracer("chat-service"),
+		metrics:  telemetry.NewGenAIMetrics(telemetry.GetMeter("chat-service")),
+	}
+}
+
+// newRequest 创建带默认请求参数的模型请求
+func (cs *ChatService) newRequest(req ChatRequest) Request {
+	return Request{
+		Model:       cs.model,
+		Messages:    []Message{{Role: "user", Content: req.Message}},
+		MaxTokens:   2048,
+		Temperature: 0.7,
+		TopP:        1.0,
+		Seed:        42,
 	}
 }
 
 func (cs *ChatService) ProcessChat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
-	conversationID := uuid.New().String()
-	_, span := cs.tracer.Start(ctx, "chat.process",
-		trace.WithAttributes(
-			semconv.GenAIOperationNameChat,
-			semconv.GenAIProviderNameOpenAI,
-			semconv.GenAIRequestModel("gpt-3.5-turbo"),
-			semconv.GenAIConversationID(conversationID),
-			semconv.GenAIInputMessagesKey.String(fmt.Sprintf(`[{"role":"user","content":"%s"}]`, req.Message)),
-		),
-	)
-	defer span.End()
-
-	time.Sleep(100 * time.Millisecond)
-
-	// 根据用户消息生成合适的AI回复
-	var reply string
-	message := req.Message
-
-	// 检查多个关键词，优先级高的先匹配
-	switch {
-	case contains(message, "Go语言") || contains(message, "Golang") || (contains(message, "Go") && contains(message, "语言")):
-		reply = "Go语言是Google开发的一种静态强类型、编译型语言。它具有简洁的语法、高效的并发处理能力和优秀的性能，非常适合构建网络服务和分布式系统。"
-	case contains(message, "天气"):
-		reply = "我无法获取实时天气信息，但您可以使用天气查询工具来获取准确的天气数据。"
-	case contains(message, "谢谢"):
-		reply = "不客气！如果您还有其他问题，随时告诉我。"
-	case contains(message, "你好") || contains(message, "您好"):
-		reply = "你好！很高兴为您服务。我是一个AI助手，可以回答您的问题和提供帮助。"
-	default:
-		reply = fmt.Sprintf("我理解您说的是：%s。这是一个很有趣的话题，我可以为您提供更多相关信息。", message)
+	resp, err := cs.Chat(ctx, cs.newRequest(req))
+	if err != nil {
+		return nil, err
 	}
 
-	response := &ChatResponse{
-		Reply:     reply,
+	return &ChatResponse{
+		Reply:     resp.Message.Content,
 		Timestamp: time.Now(),
+	}, nil
+}
+
+// Chat 调用后端并记录span、消息和指标，各后端共用这一份埋点
+func (cs *ChatService) Chat(ctx context.Context, req Request) (Response, error) {
+	call := cs.startCall(ctx, req)
+	resp, err := cs.provider.Chat(call.ctx, call.req)
+	call.end(resp, err)
+	if err != nil {
+		return Response{}, err
+	}
+	return resp, nil
+}
+
+// chatCall 一次模型调用的埋点状态，Chat和ChatStream共用
+type chatCall struct {
+	cs             *ChatService
+	ctx            context.Context
+	span           trace.Span
+	req            Request
+	start          time.Time
+	conversationID string
+	providerName   attribute.KeyValue
+	metricAttrs    telemetry.GenAIMetricAttrs
+}
+
+// startCall 创建chat span并记录请求属性，后端实现RequestAdapter时先调整请求，
+// 调用方应向后端发送call.req
+func (cs *ChatService) startCall(ctx context.Context, req Request) *chatCall {
+	if adapter, ok := cs.provider.(RequestAdapter); ok {
+		req = adapter.AdaptRequest(req)
+	}
+	call := &chatCall{
+		cs:             cs,
+		req:            req,
+		conversationID: uuid.New().String(),
+		providerName:   semconv.GenAIProviderNameKey.String(cs.provider.Name()),
+		metricAttrs: telemetry.GenAIMetricAttrs{
+			OperationName: semconv.GenAIOperationNameChat.Value.AsString(),
+			ProviderName:  cs.provider.Name(),
+			RequestModel:  req.Model,
+		},
 	}
 
-	span.SetAttributes(
-		semconv.GenAIOutputMessagesKey.String(fmt.Sprintf(`[{"role":"assistant","content":"%s"}]`, response.Reply)),
-		semconv.GenAIUsageOutputTokens(len(response.Reply)),
-		semconv.GenAIUsageInputTokens(len(req.Message)),
-		semconv.GenAIResponseID(fmt.Sprintf("chatcmpl-%d", time.Now().Unix())),
-		semconv.GenAIResponseFinishReasons("stop"),
-		semconv.GenAIRequestMaxTokens(2048),
-		semconv.GenAIRequestTemperature(0.7),
-		semconv.GenAIRequestTopP(1.0),
-		semconv.GenAIRequestFrequencyPenalty(0),
-		semconv.GenAIRequestPresencePenalty(0),
-		semconv.GenAIRequestChoiceCount(1),
-		semconv.GenAIRequestSeed(42),
+	attrs := []attribute.KeyValue{
+		semconv.GenAIOperationNameChat,
+		call.providerName,
+		semconv.GenAIRequestModel(req.Model),
+		semconv.GenAIConversationID(call.conversationID),
 		semconv.GenAIOutputTypeText,
+	}
+	if addresser, ok := cs.provider.(ServerAddresser); ok {
+		call.metricAttrs.ServerAddress, call.metricAttrs.ServerPort = addresser.ServerAddress()
+		attrs = append(attrs,
+			semconv.ServerAddress(call.metricAttrs.ServerAddress),
+			semconv.ServerPort(call.metricAttrs.ServerPort),
+		)
+	}
+	attrs = append(attrs, requestAttributes(req)...)
+
+	call.start = time.Now()
+	call.ctx, call.span = cs.tracer.Start(ctx, "chat "+req.Model,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	return call
+}
+
+// end 记录响应或错误、消息和指标，并结束span
+func (c *chatCall) end(resp Response, err error) {
+	defer c.span.End()
+
+	if err != nil {
+		c.span.RecordError(err)
+		c.span.SetStatus(codes.Error, err.Error())
+		c.span.SetAttributes(semconv.ErrorTypeOther)
+		c.cs.metrics.RecordDuration(c.ctx, c.metricAttrs, time.Since(c.start), err)
+		return
+	}
+
+	inputMessages, _ := json.Marshal(c.req.Messages)
+	outputMessages, _ := json.Marshal([]outputMessage{{Message: resp.Message, FinishReason: resp.FinishReason}})
+
+	// 输入输出消息写入span属性，或在启用GenAI事件时以日志事件发送
+	telemetry.RecordMessages(c.ctx, c.span,
+		[]attribute.KeyValue{
+			semconv.GenAIInputMessagesKey.String(string(inputMessages)),
+			semconv.GenAIOutputMessagesKey.String(string(outputMessages)),
+		},
+		semconv.GenAIOperationNameChat,
+		c.providerName,
+		semconv.GenAIRequestModel(c.req.Model),
+		semconv.GenAIConversationID(c.conversationID),
+	)
+
+	c.span.SetAttributes(
+		semconv.GenAIUsageInputTokens(resp.Usage.InputTokens),
+		semconv.GenAIUsageOutputTokens(resp.Usage.OutputTokens),
 	)
+	if resp.Usage.CacheReadInputTokens > 0 {
+		c.span.SetAttributes(attribute.Int("gen_ai.usage.cache_read.input_tokens", resp.Usage.CacheReadInputTokens))
+	}
+	if resp.Usage.CacheCreationInputTokens > 0 {
+		c.span.SetAttributes(attribute.Int("gen_ai.usage.cache_creation.input_tokens", resp.Usage.CacheCreationInputTokens))
+	}
+	if resp.ID != "" {
+		c.span.SetAttributes(semconv.GenAIResponseID(resp.ID))
+	}
+	if resp.Model != "" {
+		c.span.SetAttributes(semconv.GenAIResponseModel(resp.Model))
+		c.metricAttrs.ResponseModel = resp.Model
+	}
+	if resp.FinishReason != "" {
+		c.span.SetAttributes(semconv.GenAIResponseFinishReasons(resp.FinishReason))
+	}
+
+	c.cs.metrics.RecordTokenUsage(c.ctx, c.metricAttrs, resp.Usage.InputTokens, resp.Usage.OutputTokens)
+	c.cs.metrics.RecordDuration(c.ctx, c.metricAttrs, time.Since(c.start), nil)
+}
+
+// outputMessage 输出消息，附带结束原因
+type outputMessage struct {
+	Message
+	FinishReason string `json:"finish_reason,omitempty"`
+}
 
-	return response, nil
+// requestAttributes 返回请求中已设置参数对应的gen_ai.request.*属性
+func requestAttributes(req Request) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.GenAIRequestChoiceCount(1)}
+	if req.MaxTokens > 0 {
+		attrs = append(attrs, semconv.GenAIRequestMaxTokens(req.MaxTokens))
+	}
+	if req.Temperature != 0 {
+		attrs = append(attrs, semconv.GenAIRequestTemperature(req.Temperature))
+	}
+	if req.TopP != 0 {
+		attrs = append(attrs, semconv.GenAIRequestTopP(req.TopP))
+	}
+	if req.FrequencyPenalty != 0 {
+		attrs = append(attrs, semconv.GenAIRequestFrequencyPenalty(req.FrequencyPenalty))
+	}
+	if req.PresencePenalty != 0 {
+		attrs = append(attrs, semconv.GenAIRequestPresencePenalty(req.PresencePenalty))
+	}
+	if req.Seed != 0 {
+		attrs = append(attrs, semconv.GenAIRequestSeed(req.Seed))
+	}
+	return attrs
+}
+
+// newServiceFromEnv 根据GENAI_CHAT_PROVIDER选择后端，默认使用MockProvider
+func newServiceFromEnv() (*ChatService, error) {
+	model := os.Getenv("GENAI_CHAT_MODEL")
+	switch os.Getenv("GENAI_CHAT_PROVIDER") {
+	case "", "mock":
+		if model == "" {
+			model = chatModel
+		}
+		return NewChatServiceWithProvider(&MockProvider{}, model), nil
+	case "openai":
+		if model == "" {
+			model = chatModel
+		}
+		provider, err := NewOpenAIProvider(OpenAIConfig{
+			BaseURL: os.Getenv("OPENAI_BASE_URL"),
+			APIKey:  os.Getenv("OPENAI_API_KEY"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return NewChatServiceWithProvider(provider, model), nil
+	case "azure":
+		if model == "" {
+			model = chatModel
+		}
+		provider, err := NewOpenAIProvider(OpenAIConfig{
+			BaseURL:    os.Getenv("AZURE_OPENAI_ENDPOINT"),
+			APIKey:     os.Getenv("AZURE_OPENAI_API_KEY"),
+			Azure:      true,
+			APIVersion: os.Getenv("OPENAI_API_VERSION"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return NewChatServiceWithProvider(provider, model), nil
+	case "anthropic":
+		if model == "" {
+			model = defaultAnthropicModel
+		}
+		provider, err := NewAnthropicProvider(AnthropicConfig{
+			BaseURL: os.Getenv("ANTHROPIC_BASE_URL"),
+			APIKey:  os.Getenv("ANTHROPIC_API_KEY"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return NewChatServiceWithProvider(provider, model), nil
+	case "ollama":
+		if model == "" {
+			model = defaultOllamaModel
+		}
+		// OLLAMA_HOST与ollama命令行一致，可以不带scheme
+		baseURL := os.Getenv("OLLAMA_HOST")
+		if baseURL != "" && !strings.Contains(baseURL, "://") {
+			baseURL = "http://" + baseURL
+		}
+		provider, err := NewOllamaProvider(OllamaConfig{
+			BaseURL: baseURL,
+			Stream:  strings.EqualFold(os.Getenv("GENAI_CHAT_STREAM"), "true"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return NewChatServiceWithProvider(provider, model), nil
+	default:
+		return nil, fmt.Errorf("unsupported chat provider: %s", os.Getenv("GENAI_CHAT_PROVIDER"))
+	}
 }
 
 func RunChatMode() {
 	fmt.Println("=== 通用AI Chat模式示例 ===")
 
-	chatService := NewChatService()
+	chatService, err := newServiceFromEnv()
+	if err != nil {
+		fmt.Printf("Chat provider setup failed: %v\n", err)
+		return
+	}
 	ctx := context.Background()
 	req := ChatRequest{
 		Message: "你好，请介绍一下Go语言",
 		UserID:  "user123",
 	}
 
+	if strings.EqualFold(os.Getenv("GENAI_CHAT_STREAM"), "true") {
+		fmt.Printf("用户消息: %s\n", req.Message)
+		fmt.Print("AI回复: ")
+		for delta, err := range chatService.ProcessChatStream(ctx, req) {
+			if err != nil {
+				fmt.Printf("\nChat processing failed: %v\n", err)
+				return
+			}
+			fmt.Print(delta.Content)
+		}
+		fmt.Println()
+		return
+	}
+
 	response, err := chatService.ProcessChat(ctx, req)
 	if err != nil {
 		fmt.Printf("Chat processing failed: %v\n", err)
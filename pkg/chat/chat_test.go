@@ -0,0 +1,81 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gen-ai-example/telemetry"
+	"gen-ai-example/telemetry/telemetrytest"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// failingProvider 总是返回错误的后端
+type failingProvider struct{}
+
+func (failingProvider) Name() string { return "failing" }
+
+func (failingProvider) Chat(context.Context, Request) (Response, error) {
+	return Response{}, errors.New("backend unavailable")
+}
+
+func TestProcessChat(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+	}{
+		{name: "greeting", message: "你好"},
+		{name: "keyword", message: "介绍一下Go语言"},
+		{name: "fallback", message: "今天吃什么"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := telemetrytest.Install(t)
+			cs := NewChatService()
+
+			ctx, root := telemetry.GetTracer("test").Start(context.Background(), "test.root")
+			resp, err := cs.ProcessChat(ctx, ChatRequest{Message: tt.message})
+			root.End()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Reply == "" {
+				t.Error("empty reply")
+			}
+
+			span := recorder.Span(t, "chat "+chatModel)
+			if span.SpanKind != trace.SpanKindClient {
+				t.Errorf("span kind = %s, want client", span.SpanKind)
+			}
+			telemetrytest.AssertParent(t, recorder.Span(t, "test.root"), span)
+			telemetrytest.AssertOperationName(t, span, "chat")
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIProviderNameKey.String(mockProviderName))
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIRequestModel(chatModel))
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIResponseModel(chatModel))
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIResponseFinishReasons("stop"))
+			telemetrytest.AssertHasTokenUsage(t, span)
+			telemetrytest.AssertConformance(t, span)
+		})
+	}
+}
+
+func TestChatError(t *testing.T) {
+	recorder := telemetrytest.Install(t)
+	cs := NewChatServiceWithProvider(failingProvider{}, chatModel)
+
+	if _, err := cs.ProcessChat(context.Background(), ChatRequest{Message: "你好"}); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	span := recorder.Span(t, "chat "+chatModel)
+	if span.Status.Code != codes.Error {
+		t.Errorf("status = %s, want error", span.Status.Code)
+	}
+	telemetrytest.AssertAttribute(t, span, semconv.ErrorTypeOther)
+	telemetrytest.AssertNoAttribute(t, span, semconv.GenAIUsageInputTokensKey)
+	telemetrytest.AssertConformance(t, span)
+}
@@ -0,0 +1,107 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"gen-ai-example/telemetry"
+)
+
+// defaultDialTimeout 未指定HTTPClient时建立连接的超时
+const defaultDialTimeout = 10 * time.Second
+
+// defaultHTTPClient 未指定HTTPClient时使用的客户端
+//
+// 只限制建立连接和TLS握手的时间，不设置Client.Timeout：模型生成和流式响应的
+// 读取可能持续很久，整体期限由调用方传入的ctx控制。
+var defaultHTTPClient = &http.Client{Transport: newDefaultTransport()}
+
+// newDefaultTransport 基于http.DefaultTransport创建带连接超时的Transport
+func newDefaultTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   defaultDialTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	return transport
+}
+
+// parseServerURL 解析后端地址，返回URL和端口，未指定端口时按scheme取默认端口
+func parseServerURL(raw string) (*url.URL, int, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, 0, fmt.Errorf("invalid base URL: %q", raw)
+	}
+	if u.Port() == "" {
+		if u.Scheme == "https" {
+			return u, 443, nil
+		}
+		return u, 80, nil
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid base URL: %q", raw)
+	}
+	return u, port, nil
+}
+
+// httpClientOrDefault 返回client，为nil时返回defaultHTTPClient
+func httpClientOrDefault(client *http.Client) *http.Client {
+	if client == nil {
+		return defaultHTTPClient
+	}
+	return client
+}
+
+// postJSON 以JSON发送POST请求并注入trace上下文
+//
+// 状态码为200时返回响应，由调用方关闭Body；否则读取响应体，
+// 通过errorMessage提取错误信息后返回错误。
+func postJSON(ctx context.Context, client *http.Client, endpoint string, header http.Header, body interface{}, errorMessage func([]byte) string) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	telemetry.InjectHTTP(ctx, req.Header)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", endpoint, err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if message := errorMessage(respBody); message != "" {
+		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, message)
+	}
+	return nil, fmt.Errorf("request failed with status %d", resp.StatusCode)
+}
+
+// decodeJSON 解析响应体并关闭
+func decodeJSON(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// contains 检查字符串是否包含子字符串（不区分大小写）
+func contains(s, substr string) bool {
+	return len(s) >= len(substr) && (s == substr ||
+		len(s) > len(substr) && (containsIgnoreCase(s, substr) ||
+			containsWord(s, substr)))
+}
+
+// containsIgnoreCase 不区分大小写包含检查
+func containsIgnoreCase(s, substr string) bool {
+	s = strings.ToLower(s)
+	substr = strings.ToLower(substr)
+	return strings.Contains(s, substr)
+}
+
+// containsWord 检查是否包含完整单词
+func containsWord(s, word string) bool {
+	words := strings.Fields(s)
+	for _, w := range words {
+		if strings.Contains(strings.ToLower(w), strings.ToLower(word)) {
+			return true
+		}
+	}
+	return false
+}
+
+// mockProviderName MockProvider上报的gen_ai.provider.name
+//
+// 模拟的调用没有发往任何真实后端，使用单独的名称避免与openai的真实调用混在同一组指标和查询中。
+const mockProviderName = "mock"
+
+// MockProvider 根据关键词生成回复的模拟后端，不发起网络请求
+type MockProvider struct{}
+
+// Name 实现Provider接口
+func (p *MockProvider) Name() string {
+	return mockProviderName
+}
+
+// mockChunkRunes 流式响应中每个分片的字符数
+const mockChunkRunes = 4
+
+// sleep 等待d，ctx取消时提前返回错误
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Chat 实现Provider接口，根据最后一条用户消息生成回复
+func (p *MockProvider) Chat(ctx context.Context, req Request) (Response, error) {
+	if err := sleep(ctx, 100*time.Millisecond); err != nil {
+		return Response{}, err
+	}
+	return p.reply(req), nil
+}
+
+// ChatStream 实现StreamProvider接口，将回复按字符切分为多个分片
+func (p *MockProvider) ChatStream(ctx context.Context, req Request, onDelta func(Delta) error) (Response, error) {
+	if err := sleep(ctx, 100*time.Millisecond); err != nil {
+		return Response{}, err
+	}
+
+	resp := p.reply(req)
+	runes := []rune(resp.Message.Content)
+	for i := 0; i < len(runes); i += mockChunkRunes {
+		if i > 0 {
+			if err := sleep(ctx, 20*time.Millisecond); err != nil {
+				return Response{}, err
+			}
+		}
+		end := min(i+mockChunkRunes, len(runes))
+		if err := onDelta(Delta{Content: string(runes[i:end])}); err != nil {
+			return Response{}, err
+		}
+	}
+	return resp, nil
+}
+
+// reply 根据最后一条用户消息生成回复
+func (p *MockProvider) reply(req Request) Response {
+	var message string
+	inputTokens := 0
+	for _, m := range req.Messages {
+		inputTokens += len(m.Content)
+		if m.Role == "user" {
+			message = m.Content
+		}
+	}
+
+	// 检查多个关键词，优先级高的先匹配
+	var reply string
+	switch {
+	case contains(message, "Go语言") || contains(message, "Golang") || (contains(message, "Go") && contains(message, "语言")):
+		reply = "Go语言是Google开发的一种静态强类型、编译型语言。它具有简洁的语法、高效的并发处理能力和优秀的性能，非常适合构建网络服务和分布式系统。"
+	case contains(message, "天气"):
+		reply = "我无法获取实时天气信息，但您可以使用天气查询工具来获取准确的天气数据。"
+	case contains(message, "谢谢"):
+		reply = "不客气！如果您还有其他问题，随时告诉我。"
+	case contains(message, "你好") || contains(message, "您好"):
+		reply = "你好！很高兴为您服务。我是一个AI助手，可以回答您的问题和提供帮助。"
+	default:
+		reply = fmt.Sprintf("我理解您说的是：%s。这是一个很有趣的话题，我可以为您提供更多相关信息。", message)
+	}
+
+	return Response{
+		ID:           fmt.Sprintf("chatcmpl-%d", time.Now().Unix()),
+		Model:        req.Model,
+		Message:      Message{Role: "assistant", Content: reply},
+		FinishReason: "stop",
+		Usage: Usage{
+			InputTokens:  inputTokens,
+			OutputTokens: len(reply),
+		},
+	}
+}
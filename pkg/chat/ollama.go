@@ -0,0 +1,244 @@
+package chat
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultOllamaBaseURL Ollama本地服务的默认地址
+const DefaultOllamaBaseURL = "http://localhost:11434"
+
+// ollamaProviderName gen_ai.provider.name的取值，语义约定中没有定义Ollama
+const ollamaProviderName = "ollama"
+
+// OllamaConfig Ollama后端的配置
+type OllamaConfig struct {
+	// BaseURL 服务地址，为空时使用DefaultOllamaBaseURL
+	BaseURL string
+	// Stream 为true时以流式方式调用/api/chat，并拼接各个分片
+	Stream bool
+	// HTTPClient 为nil时使用只限制连接超时的默认客户端，请求的整体期限由ctx控制
+	HTTPClient *http.Client
+}
+
+// OllamaProvider 调用Ollama的/api/chat接口
+type OllamaProvider struct {
+	endpoint string
+	stream   bool
+	address  string
+	port     int
+	client   *http.Client
+}
+
+// NewOllamaProvider 创建Ollama后端
+func NewOllamaProvider(config OllamaConfig) (*OllamaProvider, error) {
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultOllamaBaseURL
+	}
+	u, port, err := parseServerURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/api/chat"
+
+	return &OllamaProvider{
+		endpoint: u.String(),
+		stream:   config.Stream,
+		address:  u.Hostname(),
+		port:     port,
+		client:   httpClientOrDefault(config.HTTPClient),
+	}, nil
+}
+
+// Name 实现Provider接口
+func (p *OllamaProvider) Name() string {
+	return ollamaProviderName
+}
+
+// ServerAddress 实现ServerAddresser接口
+func (p *OllamaProvider) ServerAddress() (string, int) {
+	return p.address, p.port
+}
+
+// ollamaRequest /api/chat的请求体
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Options  ollamaOptions   `json:"options"`
+	Tools    []openAITool    `json:"tools,omitempty"`
+}
+
+// ollamaOptions 模型参数，max_tokens对应num_predict
+type ollamaOptions struct {
+	NumPredict       int     `json:"num_predict,omitempty"`
+	Temperature      float64 `json:"temperature,omitempty"`
+	TopP             float64 `json:"top_p,omitempty"`
+	FrequencyPenalty float64 `json:"frequency_penalty,omitempty"`
+	PresencePenalty  float64 `json:"presence_penalty,omitempty"`
+	Seed             int     `json:"seed,omitempty"`
+}
+
+// ollamaMessage Ollama格式的消息，工具参数为JSON对象
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+}
+
+// ollamaToolCall Ollama格式的工具调用
+type ollamaToolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+// ollamaResponse /api/chat的响应，流式调用时每行一个分片，最后一个分片done为true并带有统计信息
+type ollamaResponse struct {
+	Model           string        `json:"model"`
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+	Error           string        `json:"error"`
+}
+
+// ollamaErrorMessage 从错误响应体中提取错误信息
+func ollamaErrorMessage(body []byte) string {
+	var apiErr struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &apiErr) != nil {
+		return ""
+	}
+	return apiErr.Error
+}
+
+// toOllamaMessages 将消息转换为Ollama格式
+func toOllamaMessages(messages []Message) []ollamaMessage {
+	result := make([]ollamaMessage, 0, len(messages))
+	for _, m := range messages {
+		message := ollamaMessage{Role: m.Role, Content: m.Content}
+		for _, call := range m.ToolCalls {
+			var toolCall ollamaToolCall
+			toolCall.Function.Name = call.Name
+			toolCall.Function.Arguments = call.Arguments
+			if len(toolCall.Function.Arguments) == 0 {
+				toolCall.Function.Arguments = json.RawMessage("{}")
+			}
+			message.ToolCalls = append(message.ToolCalls, toolCall)
+		}
+		result = append(result, message)
+	}
+	return result
+}
+
+// request 构造/api/chat的请求体
+func (p *OllamaProvider) request(req Request, stream bool) ollamaRequest {
+	body := ollamaRequest{
+		Model:    req.Model,
+		Messages: toOllamaMessages(req.Messages),
+		Stream:   stream,
+		Options: ollamaOptions{
+			NumPredict:       req.MaxTokens,
+			Temperature:      req.Temperature,
+			TopP:             req.TopP,
+			FrequencyPenalty: req.FrequencyPenalty,
+			PresencePenalty:  req.PresencePenalty,
+			Seed:             req.Seed,
+		},
+	}
+	for _, tool := range req.Tools {
+		body.Tools = append(body.Tools, openAITool{Type: "function", Function: tool})
+	}
+	return body
+}
+
+// Chat 实现Provider接口，配置了Stream时以流式方式调用并拼接分片
+func (p *OllamaProvider) Chat(ctx context.Context, req Request) (Response, error) {
+	if p.stream {
+		return p.ChatStream(ctx, req, nil)
+	}
+
+	httpResp, err := postJSON(ctx, p.client, p.endpoint, nil, p.request(req, false), ollamaErrorMessage)
+	if err != nil {
+		return Response{}, fmt.Errorf("ollama chat request failed: %w", err)
+	}
+
+	var result ollamaResponse
+	if err := decodeJSON(httpResp, &result); err != nil {
+		return Response{}, err
+	}
+	return result.response(result.Message.Content), nil
+}
+
+// ChatStream 实现StreamProvider接口，onDelta为nil时只拼接分片
+func (p *OllamaProvider) ChatStream(ctx context.Context, req Request, onDelta func(Delta) error) (Response, error) {
+	httpResp, err := postJSON(ctx, p.client, p.endpoint, nil, p.request(req, true), ollamaErrorMessage)
+	if err != nil {
+		return Response{}, fmt.Errorf("ollama chat request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	// 流式响应按行拼接内容，统计信息取自最后一个分片
+	var content strings.Builder
+	var toolCalls []ollamaToolCall
+	scanner := bufio.NewScanner(httpResp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var chunk ollamaResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return Response{}, fmt.Errorf("failed to decode stream chunk: %w", err)
+		}
+		if chunk.Error != "" {
+			return Response{}, fmt.Errorf("ollama stream failed: %s", chunk.Error)
+		}
+		content.WriteString(chunk.Message.Content)
+		toolCalls = append(toolCalls, chunk.Message.ToolCalls...)
+		if onDelta != nil && chunk.Message.Content != "" {
+			if err := onDelta(Delta{Content: chunk.Message.Content}); err != nil {
+				return Response{}, err
+			}
+		}
+		if chunk.Done {
+			chunk.Message.ToolCalls = toolCalls
+			return chunk.response(content.String()), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Response{}, fmt.Errorf("failed to read stream: %w", err)
+	}
+	return Response{}, errors.New("ollama stream ended before done")
+}
+
+// response 转换为Response，content为完整的回复内容
+func (r ollamaResponse) response(content string) Response {
+	message := Message{Role: r.Message.Role, Content: content}
+	if message.Role == "" {
+		message.Role = "assistant"
+	}
+	for _, call := range r.Message.ToolCalls {
+		message.ToolCalls = append(message.ToolCalls, ToolCall{Name: call.Function.Name, Arguments: call.Function.Arguments})
+	}
+	return Response{
+		Model:        r.Model,
+		Message:      message,
+		FinishReason: r.DoneReason,
+		Usage: Usage{
+			InputTokens:  r.PromptEvalCount,
+			OutputTokens: r.EvalCount,
+		},
+	}
+}
@@ -0,0 +1,149 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gen-ai-example/telemetry/telemetrytest"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+func TestOllamaProvider(t *testing.T) {
+	const (
+		response = `{"model":"llama3.2","message":{"role":"assistant","content":"你好！"},"done":true,"done_reason":"stop","prompt_eval_count":26,"eval_count":4}`
+		stream   = `{"model":"llama3.2","message":{"role":"assistant","content":"你"},"done":false}
+{"model":"llama3.2","message":{"role":"assistant","content":"好！"},"done":false}
+{"model":"llama3.2","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":26,"eval_count":4}
+`
+	)
+
+	tests := []struct {
+		name     string
+		stream   bool
+		response string
+	}{
+		{name: "non-streaming", response: response},
+		{name: "streaming", stream: true, response: stream},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := telemetrytest.Install(t)
+			server, received := newTestServer(t, http.StatusOK, tt.response)
+
+			provider, err := NewOllamaProvider(OllamaConfig{BaseURL: server.URL, Stream: tt.stream})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cs := NewChatServiceWithProvider(provider, defaultOllamaModel)
+
+			resp, err := cs.Chat(context.Background(), Request{
+				Model:     defaultOllamaModel,
+				Messages:  []Message{{Role: "user", Content: "你好"}},
+				MaxTokens: 128,
+				Seed:      7,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Message.Content != "你好！" {
+				t.Errorf("content = %q, want 你好！", resp.Message.Content)
+			}
+
+			if received.path != "/api/chat" {
+				t.Errorf("path = %s, want /api/chat", received.path)
+			}
+			if received.body["stream"] != tt.stream {
+				t.Errorf("request stream = %v, want %v", received.body["stream"], tt.stream)
+			}
+			options, _ := received.body["options"].(map[string]interface{})
+			if options["num_predict"] != 128.0 || options["seed"] != 7.0 {
+				t.Errorf("request options = %v", options)
+			}
+
+			address, port := serverAddress(t, server)
+			span := recorder.Span(t, "chat "+defaultOllamaModel)
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIProviderNameKey.String(ollamaProviderName))
+			telemetrytest.AssertAttribute(t, span, semconv.ServerAddress(address))
+			telemetrytest.AssertAttribute(t, span, semconv.ServerPort(port))
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIRequestSeed(7))
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIResponseModel(defaultOllamaModel))
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIResponseFinishReasons("stop"))
+			telemetrytest.AssertTokenUsage(t, span, 26, 4)
+			telemetrytest.AssertConformance(t, span)
+		})
+	}
+}
+
+func TestOllamaProviderError(t *testing.T) {
+	recorder := telemetrytest.Install(t)
+	server, _ := newTestServer(t, http.StatusNotFound, `{"error":"model \"llama3.2\" not found, try pulling it first"}`)
+
+	provider, err := NewOllamaProvider(OllamaConfig{BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := NewChatServiceWithProvider(provider, defaultOllamaModel)
+
+	_, err = cs.Chat(context.Background(), Request{Model: defaultOllamaModel, Messages: []Message{{Role: "user", Content: "你好"}}})
+	if err == nil || !strings.Contains(err.Error(), "status 404: model \"llama3.2\" not found") {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	span := recorder.Span(t, "chat "+defaultOllamaModel)
+	if span.Status.Code != codes.Error {
+		t.Errorf("status = %s, want error", span.Status.Code)
+	}
+	telemetrytest.AssertAttribute(t, span, semconv.ErrorTypeOther)
+	telemetrytest.AssertConformance(t, span)
+}
+
+func TestDefaultHTTPClientHasNoOverallTimeout(t *testing.T) {
+	if defaultHTTPClient.Timeout != 0 {
+		t.Errorf("default client timeout = %s, want none", defaultHTTPClient.Timeout)
+	}
+}
+
+func TestOllamaStreamDeadlineFromContext(t *testing.T) {
+	telemetrytest.Install(t)
+
+	// 发送第一个分片后不再响应，直到客户端断开
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"model":"llama3.2","message":{"role":"assistant","content":"你"},"done":false}` + "\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	provider, err := NewOllamaProvider(OllamaConfig{BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := NewChatServiceWithProvider(provider, defaultOllamaModel)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	var content strings.Builder
+	var streamErr error
+	for delta, err := range cs.ChatStream(ctx, Request{Model: defaultOllamaModel, Messages: []Message{{Role: "user", Content: "你好"}}}) {
+		if err != nil {
+			streamErr = err
+			break
+		}
+		content.WriteString(delta.Content)
+	}
+	if content.String() != "你" {
+		t.Errorf("content = %q, want 你", content.String())
+	}
+	if !errors.Is(streamErr, context.DeadlineExceeded) {
+		t.Errorf("stream error = %v, want context deadline exceeded", streamErr)
+	}
+}
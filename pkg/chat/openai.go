@@ -0,0 +1,244 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// DefaultOpenAIBaseURL OpenAI官方API地址
+const DefaultOpenAIBaseURL = "https://api.openai.com"
+
+// OpenAIConfig OpenAI兼容后端的配置
+type OpenAIConfig struct {
+	// BaseURL 服务地址，如 http://localhost:8000 或 http://litellm:4000/v1，为空时使用DefaultOpenAIBaseURL
+	BaseURL string
+	// APIKey 以Bearer令牌发送，Azure为true时以api-key请求头发送，为空时不发送
+	APIKey string
+	// Azure 为true时按Azure OpenAI方式调用：BaseURL为部署地址
+	// （如 https://{resource}.openai.azure.com/openai/deployments/{deployment}），路径后不追加/v1
+	Azure bool
+	// APIVersion 非空时作为api-version查询参数发送，Azure OpenAI的部署接口需要设置
+	APIVersion string
+	// ProviderName gen_ai.provider.name的取值，为空时使用openai，Azure为true时使用azure.ai.openai
+	ProviderName string
+	// HTTPClient 为nil时使用只限制连接超时的默认客户端，请求的整体期限由ctx控制
+	HTTPClient *http.Client
+}
+
+// OpenAIProvider 调用OpenAI兼容的/v1/chat/completions接口（OpenAI、vLLM、LiteLLM、Azure等）
+type OpenAIProvider struct {
+	endpoint string
+	apiKey   string
+	azure    bool
+	name     string
+	address  string
+	port     int
+	client   *http.Client
+}
+
+// NewOpenAIProvider 创建OpenAI兼容后端
+func NewOpenAIProvider(config OpenAIConfig) (*OpenAIProvider, error) {
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
+	u, port, err := parseServerURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// 兼容以/v1结尾的地址，Azure部署地址按原样使用
+	path := strings.TrimSuffix(u.Path, "/")
+	if !config.Azure && !strings.HasSuffix(path, "/v1") {
+		path += "/v1"
+	}
+	u.Path = path + "/chat/completions"
+	if config.APIVersion != "" {
+		query := u.Query()
+		query.Set("api-version", config.APIVersion)
+		u.RawQuery = query.Encode()
+	}
+
+	name := config.ProviderName
+	if name == "" {
+		if config.Azure {
+			name = semconv.GenAIProviderNameAzureAIOpenAI.Value.AsString()
+		} else {
+			name = semconv.GenAIProviderNameOpenAI.Value.AsString()
+		}
+	}
+
+	return &OpenAIProvider{
+		endpoint: u.String(),
+		apiKey:   config.APIKey,
+		azure:    config.Azure,
+		name:     name,
+		address:  u.Hostname(),
+		port:     port,
+		client:   httpClientOrDefault(config.HTTPClient),
+	}, nil
+}
+
+// Name 实现Provider接口
+func (p *OpenAIProvider) Name() string {
+	return p.name
+}
+
+// ServerAddress 实现ServerAddresser接口
+func (p *OpenAIProvider) ServerAddress() (string, int) {
+	return p.address, p.port
+}
+
+// openAIRequest /v1/chat/completions的请求体
+type openAIRequest struct {
+	Model            string          `json:"model"`
+	Messages         []openAIMessage `json:"messages"`
+	MaxTokens        int             `json:"max_tokens,omitempty"`
+	Temperature      float64         `json:"temperature,omitempty"`
+	TopP             float64         `json:"top_p,omitempty"`
+	FrequencyPenalty float64         `json:"frequency_penalty,omitempty"`
+	PresencePenalty  float64         `json:"presence_penalty,omitempty"`
+	Seed             int             `json:"seed,omitempty"`
+	Tools            []openAITool    `json:"tools,omitempty"`
+}
+
+// openAIMessage OpenAI格式的消息
+type openAIMessage struct {
+	Role       string           `json:"role"`
+	Content    string           `json:"content"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+// openAIToolCall OpenAI格式的工具调用，参数为JSON字符串
+type openAIToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// openAITool OpenAI格式的工具定义
+type openAITool struct {
+	Type     string         `json:"type"`
+	Function ToolDefinition `json:"function"`
+}
+
+// openAIResponse /v1/chat/completions的响应体
+type openAIResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Message      openAIMessage `json:"message"`
+		FinishReason string        `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens        int `json:"prompt_tokens"`
+		CompletionTokens    int `json:"completion_tokens"`
+		PromptTokensDetails struct {
+			CachedTokens int `json:"cached_tokens"`
+		} `json:"prompt_tokens_details"`
+	} `json:"usage"`
+}
+
+// openAIErrorMessage 从错误响应体中提取错误信息
+func openAIErrorMessage(body []byte) string {
+	var apiErr struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &apiErr) != nil {
+		return ""
+	}
+	return apiErr.Error.Message
+}
+
+// toOpenAIMessages 将消息转换为OpenAI格式
+func toOpenAIMessages(messages []Message) []openAIMessage {
+	result := make([]openAIMessage, 0, len(messages))
+	for _, m := range messages {
+		message := openAIMessage{Role: m.Role, Content: m.Content, ToolCallID: m.ToolCallID}
+		for _, call := range m.ToolCalls {
+			var toolCall openAIToolCall
+			toolCall.ID = call.ID
+			toolCall.Type = "function"
+			toolCall.Function.Name = call.Name
+			toolCall.Function.Arguments = string(call.Arguments)
+			message.ToolCalls = append(message.ToolCalls, toolCall)
+		}
+		result = append(result, message)
+	}
+	return result
+}
+
+// fromOpenAIMessage 将OpenAI格式的消息转换为Message
+func fromOpenAIMessage(m openAIMessage) Message {
+	message := Message{Role: m.Role, Content: m.Content, ToolCallID: m.ToolCallID}
+	for _, call := range m.ToolCalls {
+		toolCall := ToolCall{ID: call.ID, Name: call.Function.Name}
+		if json.Valid([]byte(call.Function.Arguments)) {
+			toolCall.Arguments = json.RawMessage(call.Function.Arguments)
+		}
+		message.ToolCalls = append(message.ToolCalls, toolCall)
+	}
+	return message
+}
+
+// Chat 实现Provider接口
+func (p *OpenAIProvider) Chat(ctx context.Context, req Request) (Response, error) {
+	body := openAIRequest{
+		Model:            req.Model,
+		Messages:         toOpenAIMessages(req.Messages),
+		MaxTokens:        req.MaxTokens,
+		Temperature:      req.Temperature,
+		TopP:             req.TopP,
+		FrequencyPenalty: req.FrequencyPenalty,
+		PresencePenalty:  req.PresencePenalty,
+		Seed:             req.Seed,
+	}
+	for _, tool := range req.Tools {
+		body.Tools = append(body.Tools, openAITool{Type: "function", Function: tool})
+	}
+
+	header := http.Header{}
+	if p.apiKey != "" {
+		if p.azure {
+			header.Set("api-key", p.apiKey)
+		} else {
+			header.Set("Authorization", "Bearer "+p.apiKey)
+		}
+	}
+	httpResp, err := postJSON(ctx, p.client, p.endpoint, header, body, openAIErrorMessage)
+	if err != nil {
+		return Response{}, fmt.Errorf("chat completion failed: %w", err)
+	}
+
+	var result openAIResponse
+	if err := decodeJSON(httpResp, &result); err != nil {
+		return Response{}, err
+	}
+	if len(result.Choices) == 0 {
+		return Response{}, errors.New("chat completion returned no choices")
+	}
+
+	return Response{
+		ID:           result.ID,
+		Model:        result.Model,
+		Message:      fromOpenAIMessage(result.Choices[0].Message),
+		FinishReason: result.Choices[0].FinishReason,
+		Usage: Usage{
+			InputTokens:          result.Usage.PromptTokens,
+			OutputTokens:         result.Usage.CompletionTokens,
+			CacheReadInputTokens: result.Usage.PromptTokensDetails.CachedTokens,
+		},
+	}, nil
+}
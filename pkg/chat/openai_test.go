@@ -0,0 +1,270 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gen-ai-example/telemetry/telemetrytest"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// recordedRequest 测试服务器收到的请求
+type recordedRequest struct {
+	path   string
+	query  url.Values
+	header http.Header
+	body   map[string]interface{}
+}
+
+// newTestServer 启动返回固定响应的后端，并记录收到的请求
+func newTestServer(t *testing.T, status int, response string) (*httptest.Server, *recordedRequest) {
+	t.Helper()
+
+	received := &recordedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.path = r.URL.Path
+		received.query = r.URL.Query()
+		received.header = r.Header.Clone()
+		if err := json.NewDecoder(r.Body).Decode(&received.body); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+// serverAddress 返回测试服务器的地址和端口
+func serverAddress(t *testing.T, server *httptest.Server) (string, int) {
+	t.Helper()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Hostname(), port
+}
+
+func TestOpenAIProvider(t *testing.T) {
+	const response = `{
+		"id": "chatcmpl-123",
+		"model": "gpt-4o-mini-2024-07-18",
+		"choices": [{
+			"message": {
+				"role": "assistant",
+				"content": "",
+				"tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\":\"北京\"}"}}]
+			},
+			"finish_reason": "tool_calls"
+		}],
+		"usage": {"prompt_tokens": 30, "completion_tokens": 12, "prompt_tokens_details": {"cached_tokens": 8}}
+	}`
+
+	tests := []struct {
+		name    string
+		baseURL func(*httptest.Server) string
+	}{
+		{name: "base URL", baseURL: func(s *httptest.Server) string { return s.URL }},
+		{name: "base URL with /v1", baseURL: func(s *httptest.Server) string { return s.URL + "/v1/" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := telemetrytest.Install(t)
+			server, received := newTestServer(t, http.StatusOK, response)
+
+			provider, err := NewOpenAIProvider(OpenAIConfig{BaseURL: tt.baseURL(server), APIKey: "sk-test"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cs := NewChatServiceWithProvider(provider, "gpt-4o-mini")
+
+			resp, err := cs.Chat(context.Background(), Request{
+				Model:       "gpt-4o-mini",
+				Messages:    []Message{{Role: "user", Content: "北京天气怎么样"}},
+				MaxTokens:   256,
+				Temperature: 0.2,
+				Tools: []ToolDefinition{{
+					Name:       "get_weather",
+					Parameters: json.RawMessage(`{"type":"object"}`),
+				}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// 请求映射
+			if received.path != "/v1/chat/completions" {
+				t.Errorf("path = %s, want /v1/chat/completions", received.path)
+			}
+			if got := received.header.Get("Authorization"); got != "Bearer sk-test" {
+				t.Errorf("Authorization = %q, want Bearer sk-test", got)
+			}
+			for key, want := range map[string]interface{}{"model": "gpt-4o-mini", "max_tokens": 256.0, "temperature": 0.2} {
+				if received.body[key] != want {
+					t.Errorf("request %s = %v, want %v", key, received.body[key], want)
+				}
+			}
+			for _, key := range []string{"top_p", "seed", "frequency_penalty", "presence_penalty"} {
+				if _, ok := received.body[key]; ok {
+					t.Errorf("request has unset parameter %s", key)
+				}
+			}
+			tools, _ := received.body["tools"].([]interface{})
+			if len(tools) != 1 || tools[0].(map[string]interface{})["type"] != "function" {
+				t.Errorf("request tools = %v", received.body["tools"])
+			}
+
+			// 响应解析
+			if len(resp.Message.ToolCalls) != 1 || string(resp.Message.ToolCalls[0].Arguments) != `{"city":"北京"}` {
+				t.Errorf("tool calls = %+v", resp.Message.ToolCalls)
+			}
+
+			address, port := serverAddress(t, server)
+			span := recorder.Span(t, "chat gpt-4o-mini")
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIProviderNameOpenAI)
+			telemetrytest.AssertAttribute(t, span, semconv.ServerAddress(address))
+			telemetrytest.AssertAttribute(t, span, semconv.ServerPort(port))
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIRequestMaxTokens(256))
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIRequestTemperature(0.2))
+			telemetrytest.AssertNoAttribute(t, span, semconv.GenAIRequestSeedKey)
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIResponseID("chatcmpl-123"))
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIResponseModel("gpt-4o-mini-2024-07-18"))
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIResponseFinishReasons("tool_calls"))
+			telemetrytest.AssertTokenUsage(t, span, 30, 12)
+			telemetrytest.AssertAttribute(t, span, attribute.Int("gen_ai.usage.cache_read.input_tokens", 8))
+			telemetrytest.AssertConformance(t, span)
+		})
+	}
+}
+
+func TestOpenAIProviderAzure(t *testing.T) {
+	const response = `{"id":"chatcmpl-1","model":"gpt-4o-mini","choices":[{"message":{"role":"assistant","content":"你好"},"finish_reason":"stop"}]}`
+
+	tests := []struct {
+		name       string
+		apiVersion string
+		path       string
+	}{
+		{name: "deployment", apiVersion: "2024-10-21", path: "/openai/deployments/gpt-4o-mini/chat/completions"},
+		{name: "v1 without api-version", path: "/openai/v1/chat/completions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := telemetrytest.Install(t)
+			server, received := newTestServer(t, http.StatusOK, response)
+
+			baseURL := server.URL + strings.TrimSuffix(tt.path, "/chat/completions")
+			provider, err := NewOpenAIProvider(OpenAIConfig{
+				BaseURL:    baseURL,
+				APIKey:     "azure-key",
+				Azure:      true,
+				APIVersion: tt.apiVersion,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cs := NewChatServiceWithProvider(provider, "gpt-4o-mini")
+
+			if _, err := cs.Chat(context.Background(), Request{
+				Model:    "gpt-4o-mini",
+				Messages: []Message{{Role: "user", Content: "你好"}},
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			if received.path != tt.path {
+				t.Errorf("path = %s, want %s", received.path, tt.path)
+			}
+			if got := received.query.Get("api-version"); got != tt.apiVersion {
+				t.Errorf("api-version = %q, want %q", got, tt.apiVersion)
+			}
+			if got := received.header.Get("api-key"); got != "azure-key" {
+				t.Errorf("api-key = %q, want azure-key", got)
+			}
+			if got := received.header.Get("Authorization"); got != "" {
+				t.Errorf("Authorization = %q, want none for Azure", got)
+			}
+
+			span := recorder.Span(t, "chat gpt-4o-mini")
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIProviderNameAzureAIOpenAI)
+			telemetrytest.AssertConformance(t, span)
+		})
+	}
+}
+
+func TestOpenAIProviderError(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		want     string
+	}{
+		{
+			name:     "error message",
+			status:   http.StatusUnauthorized,
+			response: `{"error":{"message":"Incorrect API key provided"}}`,
+			want:     "chat completion failed: request failed with status 401: Incorrect API key provided",
+		},
+		{
+			name:     "no error body",
+			status:   http.StatusBadGateway,
+			response: `bad gateway`,
+			want:     "chat completion failed: request failed with status 502",
+		},
+		{
+			name:     "no choices",
+			status:   http.StatusOK,
+			response: `{"id":"chatcmpl-1","choices":[]}`,
+			want:     "chat completion returned no choices",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := telemetrytest.Install(t)
+			server, received := newTestServer(t, tt.status, tt.response)
+
+			provider, err := NewOpenAIProvider(OpenAIConfig{BaseURL: server.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cs := NewChatServiceWithProvider(provider, "gpt-4o-mini")
+
+			_, err = cs.Chat(context.Background(), Request{
+				Model:    "gpt-4o-mini",
+				Messages: []Message{{Role: "user", Content: "你好"}},
+			})
+			if err == nil || err.Error() != tt.want {
+				t.Fatalf("Chat() error = %v, want %s", err, tt.want)
+			}
+			if got := received.header.Get("Authorization"); got != "" {
+				t.Errorf("Authorization = %q, want none without an API key", got)
+			}
+
+			span := recorder.Span(t, "chat gpt-4o-mini")
+			if span.Status.Code != codes.Error || span.Status.Description != tt.want {
+				t.Errorf("status = %s %q, want error %q", span.Status.Code, span.Status.Description, tt.want)
+			}
+			telemetrytest.AssertAttribute(t, span, semconv.ErrorTypeOther)
+			telemetrytest.AssertNoAttribute(t, span, semconv.GenAIResponseIDKey)
+			telemetrytest.AssertConformance(t, span)
+		})
+	}
+}
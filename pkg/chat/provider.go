@@ -0,0 +1,87 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Message 一条对话消息
+//
+// Role取值为system、user、assistant或tool，tool消息携带工具调用的结果。
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+	// ToolCalls assistant消息中模型发起的工具调用
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID tool消息对应的工具调用ID
+	ToolCallID string `json:"tool_call_id,omitempty"`
+}
+
+// ToolCall 模型发起的一次工具调用
+type ToolCall struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// ToolDefinition 请求中提供给模型的工具
+type ToolDefinition struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+// Request 一次模型调用的请求
+//
+// 数值参数为0时表示使用模型的默认值，不写入请求。
+type Request struct {
+	Model            string
+	Messages         []Message
+	MaxTokens        int
+	Temperature      float64
+	TopP             float64
+	FrequencyPenalty float64
+	PresencePenalty  float64
+	Seed             int
+	Tools            []ToolDefinition
+}
+
+// Usage 模型调用消耗的tokens数量
+//
+// InputTokens包含命中缓存和写入缓存的输入tokens。
+type Usage struct {
+	InputTokens              int
+	OutputTokens             int
+	CacheReadInputTokens     int
+	CacheCreationInputTokens int
+}
+
+// Response 一次模型调用的响应
+type Response struct {
+	ID           string
+	Model        string
+	Message      Message
+	FinishReason string
+	Usage        Usage
+}
+
+// Provider 模型服务的后端，ChatService在其外统一记录遥测数据
+type Provider interface {
+	// Name 返回gen_ai.provider.name的取值
+	Name() string
+	// Chat 发送一次对话请求
+	Chat(ctx context.Context, req Request) (Response, error)
+}
+
+// ServerAddresser 由远程后端实现，返回服务的地址和端口，用于server.address和server.port属性
+type ServerAddresser interface {
+	ServerAddress() (address string, port int)
+}
+
+// RequestAdapter 由只支持部分请求参数的后端实现，返回实际发送给后端的请求
+//
+// ChatService在记录gen_ai.request.*属性和调用后端之前使用调整后的请求，
+// 保证span上的请求参数与实际发送的一致。
+type RequestAdapter interface {
+	AdaptRequest(req Request) Request
+}
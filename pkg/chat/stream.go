@@ -0,0 +1,110 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Delta 流式响应中的一段增量内容
+type Delta struct {
+	Content string
+}
+
+// StreamProvider 支持流式响应的后端，未实现该接口的后端在流式调用时一次返回完整回复
+type StreamProvider interface {
+	Provider
+	// ChatStream 以流式方式发送请求，每收到一段内容调用onDelta，返回拼接后的完整响应
+	//
+	// onDelta返回错误时应停止读取并原样返回该错误。
+	ChatStream(ctx context.Context, req Request, onDelta func(Delta) error) (Response, error)
+}
+
+// errStreamStopped 消费方提前结束迭代时由onDelta返回，用于中止后端的读取
+var errStreamStopped = errors.New("stream stopped by consumer")
+
+// ProcessChatStream 以流式方式处理聊天请求，返回增量内容的迭代器
+func (cs *ChatService) ProcessChatStream(ctx context.Context, req ChatRequest) iter.Seq2[Delta, error] {
+	return cs.ChatStream(ctx, cs.newRequest(req))
+}
+
+// ChatStream 以流式方式调用后端，返回增量内容的迭代器
+//
+// span在迭代开始时创建，直到流结束才结束：收到第一个分片时记录first_chunk事件和
+// gen_ai.server.time_to_first_token，结束时记录gen_ai.server.time_per_output_token。
+// 出错时迭代器最后产出一个错误；消费方提前break时取消后端请求，span标记为
+// chat.stream.cancelled，不视为失败。调用方取消ctx同样按取消处理，迭代器最后产出ctx的错误。
+func (cs *ChatService) ChatStream(ctx context.Context, req Request) iter.Seq2[Delta, error] {
+	return func(yield func(Delta, error) bool) {
+		call := cs.startCall(ctx, req)
+		// 消费方提前结束时立即取消后端请求，不依赖后端处理onDelta返回的错误
+		providerCtx, cancelProvider := context.WithCancel(call.ctx)
+		defer cancelProvider()
+
+		var firstChunk time.Time
+		stopped := false
+		onDelta := func(delta Delta) error {
+			if firstChunk.IsZero() {
+				firstChunk = time.Now()
+				ttft := firstChunk.Sub(call.start)
+				call.span.AddEvent("first_chunk", trace.WithTimestamp(firstChunk), trace.WithAttributes(
+					attribute.Float64("gen_ai.server.time_to_first_token", ttft.Seconds()),
+				))
+				cs.metrics.RecordTimeToFirstToken(call.ctx, call.metricAttrs, ttft)
+			}
+			// 后端忽略了上次返回的错误时不再调用yield，否则range循环会panic
+			if stopped {
+				return errStreamStopped
+			}
+			if !yield(delta, nil) {
+				stopped = true
+				cancelProvider()
+				return errStreamStopped
+			}
+			return nil
+		}
+
+		var resp Response
+		var err error
+		if provider, ok := cs.provider.(StreamProvider); ok {
+			resp, err = provider.ChatStream(providerCtx, call.req, onDelta)
+		} else {
+			resp, err = cs.provider.Chat(providerCtx, call.req)
+			if err == nil {
+				err = onDelta(Delta{Content: resp.Message.Content})
+			}
+		}
+
+		if stopped {
+			call.cancel()
+			return
+		}
+		if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+			call.cancel()
+			yield(Delta{}, ctx.Err())
+			return
+		}
+		if err == nil && resp.Usage.OutputTokens > 1 && !firstChunk.IsZero() {
+			perToken := time.Since(firstChunk) / time.Duration(resp.Usage.OutputTokens-1)
+			if resp.Model != "" {
+				call.metricAttrs.ResponseModel = resp.Model
+			}
+			cs.metrics.RecordTimePerOutputToken(call.ctx, call.metricAttrs, perToken)
+		}
+		call.end(resp, err)
+		if err != nil {
+			yield(Delta{}, err)
+		}
+	}
+}
+
+// cancel 消费方提前结束流时结束span，只记录耗时
+func (c *chatCall) cancel() {
+	defer c.span.End()
+	c.span.SetAttributes(attribute.Bool("chat.stream.cancelled", true))
+	c.cs.metrics.RecordDuration(c.ctx, c.metricAttrs, time.Since(c.start), nil)
+}
@@ -0,0 +1,146 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gen-ai-example/telemetry/telemetrytest"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// ignoringProvider 忽略onDelta返回的错误、继续发送分片的后端
+type ignoringProvider struct{}
+
+func (ignoringProvider) Name() string { return "ignoring" }
+
+func (ignoringProvider) Chat(context.Context, Request) (Response, error) {
+	return Response{Message: Message{Role: "assistant", Content: "abc"}}, nil
+}
+
+func (ignoringProvider) ChatStream(_ context.Context, _ Request, onDelta func(Delta) error) (Response, error) {
+	for _, content := range []string{"a", "b", "c"} {
+		_ = onDelta(Delta{Content: content})
+	}
+	return Response{Message: Message{Role: "assistant", Content: "abc"}}, nil
+}
+
+// waitingProvider 发送一个分片后忽略onDelta返回的错误，一直等到请求被取消
+type waitingProvider struct {
+	// cancelled 请求的ctx被取消时写入true，超时未取消时写入false
+	cancelled chan bool
+}
+
+func (waitingProvider) Name() string { return "waiting" }
+
+func (waitingProvider) Chat(context.Context, Request) (Response, error) {
+	return Response{Message: Message{Role: "assistant", Content: "a"}}, nil
+}
+
+func (p waitingProvider) ChatStream(ctx context.Context, _ Request, onDelta func(Delta) error) (Response, error) {
+	_ = onDelta(Delta{Content: "a"})
+	select {
+	case <-ctx.Done():
+		p.cancelled <- true
+		return Response{}, ctx.Err()
+	case <-time.After(5 * time.Second):
+		p.cancelled <- false
+		return Response{}, nil
+	}
+}
+
+// assertCancelled 断言流式span标记为取消且没有错误状态
+func assertCancelled(t *testing.T, span tracetest.SpanStub) {
+	t.Helper()
+
+	telemetrytest.AssertAttribute(t, span, attribute.Bool("chat.stream.cancelled", true))
+	if span.Status.Code == codes.Error {
+		t.Errorf("status = %s %q, want no error", span.Status.Code, span.Status.Description)
+	}
+	telemetrytest.AssertNoAttribute(t, span, "error.type")
+}
+
+func TestChatStream(t *testing.T) {
+	recorder := telemetrytest.Install(t)
+	cs := NewChatService()
+
+	var content strings.Builder
+	for delta, err := range cs.ProcessChatStream(context.Background(), ChatRequest{Message: "你好"}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		content.WriteString(delta.Content)
+	}
+
+	span := recorder.Span(t, "chat "+chatModel)
+	if got, ok := telemetrytest.Attribute(span, "gen_ai.output.messages"); ok && !strings.Contains(got.AsString(), content.String()) {
+		t.Errorf("output messages %s do not contain streamed content %q", got.AsString(), content.String())
+	}
+	if len(span.Events) == 0 || span.Events[0].Name != "first_chunk" {
+		t.Fatalf("events = %v, want first_chunk", span.Events)
+	}
+	var ttft bool
+	for _, kv := range span.Events[0].Attributes {
+		ttft = ttft || kv.Key == "gen_ai.server.time_to_first_token"
+	}
+	if !ttft {
+		t.Error("first_chunk event is missing gen_ai.server.time_to_first_token")
+	}
+	telemetrytest.AssertNoAttribute(t, span, "chat.stream.cancelled")
+	telemetrytest.AssertHasTokenUsage(t, span)
+	telemetrytest.AssertConformance(t, span)
+}
+
+func TestChatStreamCancel(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider Provider
+		cancel   bool
+		wantErr  error
+	}{
+		{name: "consumer breaks", provider: &MockProvider{}},
+		{name: "provider ignores stop", provider: ignoringProvider{}},
+		{name: "provider waits for cancellation", provider: waitingProvider{cancelled: make(chan bool, 1)}},
+		{name: "caller cancels context", provider: &MockProvider{}, cancel: true, wantErr: context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := telemetrytest.Install(t)
+			cs := NewChatServiceWithProvider(tt.provider, chatModel)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			deltas := 0
+			var streamErr error
+			for _, err := range cs.ProcessChatStream(ctx, ChatRequest{Message: "介绍一下Go语言"}) {
+				if err != nil {
+					streamErr = err
+					break
+				}
+				deltas++
+				if !tt.cancel {
+					break
+				}
+				cancel()
+			}
+
+			if deltas != 1 {
+				t.Errorf("received %d deltas, want 1", deltas)
+			}
+			if !errors.Is(streamErr, tt.wantErr) {
+				t.Errorf("stream error = %v, want %v", streamErr, tt.wantErr)
+			}
+			if waiting, ok := tt.provider.(waitingProvider); ok && !<-waiting.cancelled {
+				t.Error("provider request was not cancelled after the consumer stopped")
+			}
+			assertCancelled(t, recorder.Span(t, "chat "+chatModel))
+		})
+	}
+}
@@ -10,10 +10,14 @@ import (
 
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
+// chatModel 模拟调用的模型名称
+const chatModel = "gpt-3.5-turbo"
+
 type Tool interface {
 	Name() string
 	Description() string
@@ -96,14 +100,16 @@ func (c *CalculatorTool) Execute(ctx context.Context, params map[string]interfac
 }
 
 type ToolService struct {
-	tools  map[string]Tool
-	tracer trace.Tracer
+	tools   map[string]Tool
+	tracer  trace.Tracer
+	metrics *telemetry.GenAIMetrics
 }
 
 func NewToolService() *ToolService {
 	ts := &ToolService{
-		tools:  make(map[string]Tool),
-		tracer: telemetry.GetTracer("tool-service"),
+		tools:   make(map[string]Tool),
+		tracer:  telemetry.GetTracer("tool-service"),
+		metrics: telemetry.NewGenAIMetrics(telemetry.GetMeter("tool-service")),
 	}
 
 	ts.RegisterTool(&WeatherTool{})
@@ -123,13 +129,14 @@ type ChatModelResponse struct {
 func (ts *ToolService) SimulateChatModelCall(ctx context.Context, userMessage string) (*ChatModelResponse, error) {
 	// 创建聊天模型调用追踪
 	conversationID := uuid.New().String()
-	_, span := ts.tracer.Start(ctx, "chat-model.call",
+	start := time.Now()
+	ctx, span := ts.tracer.Start(ctx, "chat "+chatModel,
+		trace.WithSpanKind(trace.SpanKindClient),
 		trace.WithAttributes(
 			semconv.GenAIOperationNameChat,
 			semconv.GenAIProviderNameOpenAI,
-			semconv.GenAIRequestModel("gpt-3.5-turbo"),
+			semconv.GenAIRequestModel(chatModel),
 			semconv.GenAIConversationID(conversationID),
-			semconv.GenAIInputMessagesKey.String(fmt.Sprintf(`[{"role":"user","content":"%s"}]`, userMessage)),
 		),
 	)
 	defer span.End()
@@ -171,8 +178,19 @@ func (ts *ToolService) SimulateChatModelCall(ctx context.Context, userMessage st
 
 	respJson, _ := json.Marshal([]ChatModelResponse{resp})
 
+	// 输入输出消息写入span属性，或在启用GenAI事件时以日志事件发送
+	telemetry.RecordMessages(ctx, span,
+		[]attribute.KeyValue{
+			semconv.GenAIInputMessagesKey.String(fmt.Sprintf(`[{"role":"user","content":"%s"}]`, userMessage)),
+			semconv.GenAIOutputMessagesKey.String(string(respJson)),
+		},
+		semconv.GenAIOperationNameChat,
+		semconv.GenAIProviderNameOpenAI,
+		semconv.GenAIRequestModel(chatModel),
+		semconv.GenAIConversationID(conversationID),
+	)
+
 	span.SetAttributes(
-		semconv.GenAIOutputMessagesKey.String(string(respJson)),
 		semconv.GenAIUsageOutputTokens(len(string(respJson))),
 		semconv.GenAIUsageInputTokens(len(userMessage)),
 		semconv.GenAIResponseID(fmt.Sprintf("chatcmpl-%d", time.Now().Unix())),
@@ -187,6 +205,14 @@ func (ts *ToolService) SimulateChatModelCall(ctx context.Context, userMessage st
 		semconv.GenAIOutputTypeText,
 	)
 
+	metricAttrs := telemetry.GenAIMetricAttrs{
+		OperationName: semconv.GenAIOperationNameChat.Value.AsString(),
+		ProviderName:  semconv.GenAIProviderNameOpenAI.Value.AsString(),
+		RequestModel:  chatModel,
+	}
+	ts.metrics.RecordTokenUsage(ctx, metricAttrs, len(userMessage), len(string(respJson)))
+	ts.metrics.RecordDuration(ctx, metricAttrs, time.Since(start), nil)
+
 	return &resp, nil
 }
 
@@ -249,7 +275,8 @@ func (ts *ToolService) RegisterTool(tool Tool) {
 }
 
 func (ts *ToolService) ExecuteTool(ctx context.Context, toolName string, params map[string]interface{}) (interface{}, error) {
-	ctx, span := ts.tracer.Start(ctx, "tool.execute",
+	ctx, span := ts.tracer.Start(ctx, "execute_tool "+toolName,
+		trace.WithSpanKind(trace.SpanKindInternal),
 		trace.WithAttributes(
 			semconv.GenAIOperationNameExecuteTool,
 			semconv.GenAIToolName(toolName),
@@ -260,27 +287,35 @@ func (ts *ToolService) ExecuteTool(ctx context.Context, toolName string, params
 
 	tool, exists := ts.tools[toolName]
 	if !exists {
-		span.RecordError(fmt.Errorf("tool not found: %s", toolName))
-		return nil, fmt.Errorf("tool not found: %s", toolName)
+		err := fmt.Errorf("tool not found: %s", toolName)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(semconv.ErrorTypeOther)
+		return nil, err
 	}
 
 	paramsJSON, _ := json.Marshal(params)
 	span.SetAttributes(
 		semconv.GenAIToolDescription(tool.Description()),
 		semconv.GenAIToolType("function"),
-		attribute.String("gen_ai.tool.params", string(paramsJSON)),
 	)
+	// 参数和结果可能包含用户数据，按内容采集策略记录
+	span.SetAttributes(telemetry.ContentAttributes(
+		attribute.String("gen_ai.tool.call.arguments", string(paramsJSON)),
+	)...)
 
 	result, err := tool.Execute(ctx, params)
 	if err != nil {
 		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(semconv.ErrorTypeOther)
 		return nil, err
 	}
 
 	resultJSON, _ := json.Marshal(result)
-	span.SetAttributes(
-		attribute.String("gen_ai.tool.result", string(resultJSON)),
-	)
+	span.SetAttributes(telemetry.ContentAttributes(
+		attribute.String("gen_ai.tool.call.result", string(resultJSON)),
+	)...)
 
 	return result, nil
 }
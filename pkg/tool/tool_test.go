@@ -0,0 +1,116 @@
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"gen-ai-example/telemetry"
+	"gen-ai-example/telemetry/telemetrytest"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestExecuteTool(t *testing.T) {
+	tests := []struct {
+		name     string
+		tool     string
+		params   map[string]interface{}
+		wantErr  bool
+		wantType bool
+	}{
+		{
+			name:     "weather",
+			tool:     "get_weather",
+			params:   map[string]interface{}{"city": "北京"},
+			wantType: true,
+		},
+		{
+			name:     "calculator",
+			tool:     "calculator",
+			params:   map[string]interface{}{"operation": "add", "a": 10.0, "b": 25.0},
+			wantType: true,
+		},
+		{
+			name:     "calculator error",
+			tool:     "calculator",
+			params:   map[string]interface{}{"operation": "divide", "a": 1.0, "b": 0.0},
+			wantErr:  true,
+			wantType: true,
+		},
+		{
+			name:    "unknown tool",
+			tool:    "search",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := telemetrytest.Install(t)
+			ts := NewToolService()
+
+			ctx, root := telemetry.GetTracer("test").Start(context.Background(), "test.root")
+			_, err := ts.ExecuteTool(ctx, tt.tool, tt.params)
+			root.End()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExecuteTool() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			span := recorder.Span(t, "execute_tool "+tt.tool)
+			if span.SpanKind != trace.SpanKindInternal {
+				t.Errorf("span kind = %s, want internal", span.SpanKind)
+			}
+			telemetrytest.AssertParent(t, recorder.Span(t, "test.root"), span)
+			telemetrytest.AssertOperationName(t, span, "execute_tool")
+			telemetrytest.AssertAttribute(t, span, semconv.GenAIToolName(tt.tool))
+			if _, ok := telemetrytest.Attribute(span, semconv.GenAIToolCallIDKey); !ok {
+				t.Errorf("missing attribute %q", semconv.GenAIToolCallIDKey)
+			}
+			if tt.wantType {
+				telemetrytest.AssertAttribute(t, span, semconv.GenAIToolType("function"))
+			}
+			if tt.wantErr {
+				if span.Status.Code != codes.Error {
+					t.Errorf("status = %s, want error", span.Status.Code)
+				}
+				telemetrytest.AssertAttribute(t, span, semconv.ErrorTypeOther)
+			}
+			telemetrytest.AssertConformance(t, span)
+		})
+	}
+}
+
+func TestExecuteToolChain(t *testing.T) {
+	recorder := telemetrytest.Install(t)
+	ts := NewToolService()
+
+	ctx, root := telemetry.GetTracer("test").Start(context.Background(), "test.root")
+	results, err := ts.ExecuteToolChain(ctx, "查询北京的天气，然后计算10+25的结果")
+	root.End()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Errorf("got %d results, want 2", len(results))
+	}
+
+	rootSpan := recorder.Span(t, "test.root")
+	chatSpan := recorder.Span(t, "chat "+chatModel)
+	weatherSpan := recorder.Span(t, "execute_tool get_weather")
+	calculatorSpan := recorder.Span(t, "execute_tool calculator")
+
+	if chatSpan.SpanKind != trace.SpanKindClient {
+		t.Errorf("chat span kind = %s, want client", chatSpan.SpanKind)
+	}
+	telemetrytest.AssertOperationName(t, chatSpan, "chat")
+	telemetrytest.AssertAttribute(t, chatSpan, semconv.GenAIProviderNameOpenAI)
+	telemetrytest.AssertAttribute(t, chatSpan, semconv.GenAIRequestModel(chatModel))
+	telemetrytest.AssertHasTokenUsage(t, chatSpan)
+
+	telemetrytest.AssertParent(t, rootSpan, chatSpan)
+	telemetrytest.AssertParent(t, rootSpan, weatherSpan)
+	telemetrytest.AssertParent(t, rootSpan, calculatorSpan)
+	telemetrytest.AssertConformance(t, chatSpan, weatherSpan, calculatorSpan)
+}
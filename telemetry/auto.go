@@ -0,0 +1,144 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	// autoProbeTimeout 启动时探测collector的超时时间
+	autoProbeTimeout = 500 * time.Millisecond
+	// maxAutoExportFailures auto导出器连续失败多少次后切换到console
+	maxAutoExportFailures = 3
+)
+
+// usesAutoExporter 判断配置中是否有信号使用auto导出器
+func (c Config) usesAutoExporter() bool {
+	for _, spec := range c.exporterSpecs() {
+		if spec.Type == ExporterAuto {
+			return true
+		}
+	}
+	return c.MetricsExporterType == ExporterAuto || c.LogsExporterType == ExporterAuto
+}
+
+// resolveAutoExporter 探测collector是否可达，不可达时将所有auto导出器替换为console
+//
+// 探测结果只在启动时记录一次日志。
+func resolveAutoExporter(ctx context.Context, config Config) Config {
+	if !config.usesAutoExporter() {
+		return config
+	}
+
+	// 默认端点只用于探测，不写回config，以免同时使用的gRPC导出器也连接到HTTP端口
+	probe := config
+	if probe.Endpoint == "" {
+		probe.Endpoint = DefaultHTTPEndpoint
+	}
+	endpoint, err := probe.tracesEndpoint()
+	if err == nil {
+		err = probeCollector(ctx, endpoint, autoProbeTimeout)
+	}
+	if err == nil {
+		log.Printf("Auto exporter: collector at %s is reachable, using OTLP/HTTP", endpoint.host)
+		return config
+	}
+
+	log.Printf("Auto exporter: collector unreachable (%v), falling back to console", err)
+
+	exporters := make([]ExporterSpec, 0, len(config.Exporters))
+	for _, spec := range config.Exporters {
+		if spec.Type == ExporterAuto {
+			spec.Type = ExporterConsole
+		}
+		exporters = append(exporters, spec)
+	}
+	config.Exporters = exporters
+
+	// metric和日志的默认值取决于ExporterType，需要在替换前固定下来
+	config.MetricsExporterType = config.metricsExporterType()
+	config.LogsExporterType = config.logsExporterType()
+	for _, exporterType := range []*ExporterType{&config.ExporterType, &config.MetricsExporterType, &config.LogsExporterType} {
+		if *exporterType == ExporterAuto {
+			*exporterType = ExporterConsole
+		}
+	}
+	return config
+}
+
+// probeCollector 在timeout内尝试建立到collector的TCP连接
+func probeCollector(ctx context.Context, endpoint otlpEndpoint, timeout time.Duration) error {
+	host := endpoint.host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		// 端点未指定端口时按scheme的默认端口探测
+		port := "80"
+		if endpoint.secure {
+			port = "443"
+		}
+		host = net.JoinHostPort(host, port)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %v", host, err)
+	}
+	return conn.Close()
+}
+
+// autoExporter 优先使用OTLP导出器，连续失败maxAutoExportFailures次后永久切换到console
+type autoExporter struct {
+	primary  trace.SpanExporter
+	fallback trace.SpanExporter
+
+	failures atomic.Int32
+	switched atomic.Bool
+	once     sync.Once
+}
+
+// newAutoExporter 创建autoExporter
+func newAutoExporter(primary, fallback trace.SpanExporter) *autoExporter {
+	return &autoExporter{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+// ExportSpans 实现trace.SpanExporter接口
+func (e *autoExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	if e.switched.Load() {
+		return e.fallback.ExportSpans(ctx, spans)
+	}
+
+	err := e.primary.ExportSpans(ctx, spans)
+	if err == nil {
+		e.failures.Store(0)
+		return nil
+	}
+	if e.failures.Add(1) < maxAutoExportFailures {
+		return err
+	}
+
+	e.once.Do(func() {
+		e.switched.Store(true)
+		log.Printf("Auto exporter: OTLP export failed %d times in a row (%v), switching to console", maxAutoExportFailures, err)
+	})
+	// 当前批次改由console输出，避免丢失
+	return e.fallback.ExportSpans(ctx, spans)
+}
+
+// Shutdown 实现trace.SpanExporter接口
+func (e *autoExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.primary.Shutdown(ctx), e.fallback.Shutdown(ctx))
+}
@@ -0,0 +1,86 @@
+package telemetry
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestResolveAutoExporterKeepsSharedEndpoint(t *testing.T) {
+	config := Config{
+		ExporterType: ExporterAuto,
+		Exporters: []ExporterSpec{
+			{Type: ExporterAuto, Processor: ProcessorBatch},
+			{Type: ExporterGRPC, Processor: ProcessorBatch},
+		},
+	}
+
+	resolved := resolveAutoExporter(context.Background(), config)
+	if resolved.Endpoint != "" {
+		t.Errorf("Endpoint = %q, want it left unset for the gRPC exporter", resolved.Endpoint)
+	}
+}
+
+func TestResolveAutoExporterUsesReachableCollector(t *testing.T) {
+	server, received := newOTLPReceiver(t)
+
+	config := Config{
+		ExporterType:        ExporterAuto,
+		Exporters:           []ExporterSpec{{Type: ExporterAuto, Processor: ProcessorSimple}},
+		Endpoint:            server.URL,
+		MetricsExporterType: ExporterNone,
+		LogsExporterType:    ExporterNone,
+	}
+
+	resolved := resolveAutoExporter(context.Background(), config)
+	if !reflect.DeepEqual(resolved, config) {
+		t.Fatalf("resolveAutoExporter() = %+v, want the config unchanged", resolved)
+	}
+
+	// 保留的auto导出器通过OTLP/HTTP发送到collector
+	setupForTest(t, config)
+	_, span := GetTracer("test").Start(context.Background(), "auto")
+	span.End()
+
+	if spans := received(); len(spans) != 1 || spans[0].GetName() != "auto" {
+		t.Errorf("collector received %v, want the auto span", spans)
+	}
+}
+
+func TestResolveAutoExporterFallsBackToConsole(t *testing.T) {
+	// 关闭监听后该端口不可达
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint := "http://" + listener.Addr().String()
+	listener.Close()
+
+	config := Config{
+		ExporterType: ExporterAuto,
+		Exporters: []ExporterSpec{
+			{Type: ExporterAuto, Processor: ProcessorSimple},
+			{Type: ExporterGRPC, Processor: ProcessorBatch},
+		},
+		Endpoint: endpoint,
+	}
+
+	resolved := resolveAutoExporter(context.Background(), config)
+	want := []ExporterSpec{
+		{Type: ExporterConsole, Processor: ProcessorSimple},
+		{Type: ExporterGRPC, Processor: ProcessorBatch},
+	}
+	if !reflect.DeepEqual(resolved.Exporters, want) {
+		t.Errorf("Exporters = %v, want %v", resolved.Exporters, want)
+	}
+	if resolved.ExporterType != ExporterConsole {
+		t.Errorf("ExporterType = %s, want console", resolved.ExporterType)
+	}
+	if resolved.MetricsExporterType != ExporterNone || resolved.LogsExporterType != ExporterNone {
+		t.Errorf("metrics/logs exporters = %s/%s, want none/none", resolved.MetricsExporterType, resolved.LogsExporterType)
+	}
+	if resolved.Endpoint != endpoint {
+		t.Errorf("Endpoint = %q, want %q", resolved.Endpoint, endpoint)
+	}
+}
@@ -0,0 +1,356 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	trace2 "go.opentelemetry.io/otel/trace"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// Violation 一条GenAI语义约定违规
+type Violation struct {
+	SpanName string
+	TraceID  string
+	SpanID   string
+	// Attribute 违规涉及的属性，span名称或类型违规时为空
+	Attribute string
+	Message   string
+}
+
+// String 返回可读的违规描述
+func (v Violation) String() string {
+	if v.Attribute == "" {
+		return fmt.Sprintf("span %q (%s): %s", v.SpanName, v.SpanID, v.Message)
+	}
+	return fmt.Sprintf("span %q (%s): %s: %s", v.SpanName, v.SpanID, v.Attribute, v.Message)
+}
+
+// operationRule 某个gen_ai.operation.name对应的检查规则
+type operationRule struct {
+	// required 必需或在本项目中总是可用的条件必需属性
+	required []attribute.Key
+	// nameAttr span名称应为"{operation} {nameAttr}"，属性缺失时为"{operation}"
+	nameAttr attribute.Key
+	// kind 期望的span类型，SpanKindUnspecified表示不检查
+	kind trace2.SpanKind
+}
+
+var (
+	// inferenceRule 模型推理类操作的规则
+	inferenceRule = operationRule{
+		required: []attribute.Key{semconv.GenAIProviderNameKey, semconv.GenAIRequestModelKey},
+		nameAttr: semconv.GenAIRequestModelKey,
+		kind:     trace2.SpanKindClient,
+	}
+
+	// operationRules 按gen_ai.operation.name划分的检查规则
+	operationRules = map[string]operationRule{
+		"chat":             inferenceRule,
+		"text_completion":  inferenceRule,
+		"generate_content": inferenceRule,
+		"embeddings":       inferenceRule,
+		"create_agent": {
+			required: []attribute.Key{semconv.GenAIProviderNameKey},
+			nameAttr: semconv.GenAIAgentNameKey,
+			kind:     trace2.SpanKindClient,
+		},
+		"invoke_agent": {
+			required: []attribute.Key{semconv.GenAIProviderNameKey},
+			nameAttr: semconv.GenAIAgentNameKey,
+		},
+		"execute_tool": {
+			required: []attribute.Key{semconv.GenAIToolNameKey},
+			nameAttr: semconv.GenAIToolNameKey,
+			kind:     trace2.SpanKindInternal,
+		},
+	}
+
+	// enumValues 取值为封闭枚举的属性
+	enumValues = map[attribute.Key][]string{
+		semconv.GenAIOutputTypeKey: {"text", "json", "image", "speech"},
+		semconv.GenAIToolTypeKey:   {"function", "extension", "datastore"},
+	}
+
+	// genAIAttributeTypes GenAI语义约定定义的span属性及其类型
+	genAIAttributeTypes = map[attribute.Key]attribute.Type{
+		semconv.GenAIAgentDescriptionKey:        attribute.STRING,
+		semconv.GenAIAgentIDKey:                 attribute.STRING,
+		semconv.GenAIAgentNameKey:               attribute.STRING,
+		semconv.GenAIConversationIDKey:          attribute.STRING,
+		semconv.GenAIDataSourceIDKey:            attribute.STRING,
+		semconv.GenAIInputMessagesKey:           attribute.STRING,
+		semconv.GenAIOperationNameKey:           attribute.STRING,
+		semconv.GenAIOutputMessagesKey:          attribute.STRING,
+		semconv.GenAIOutputTypeKey:              attribute.STRING,
+		semconv.GenAIProviderNameKey:            attribute.STRING,
+		semconv.GenAIRequestChoiceCountKey:      attribute.INT64,
+		semconv.GenAIRequestEncodingFormatsKey:  attribute.STRINGSLICE,
+		semconv.GenAIRequestFrequencyPenaltyKey: attribute.FLOAT64,
+		semconv.GenAIRequestMaxTokensKey:        attribute.INT64,
+		semconv.GenAIRequestModelKey:            attribute.STRING,
+		semconv.GenAIRequestPresencePenaltyKey:  attribute.FLOAT64,
+		semconv.GenAIRequestSeedKey:             attribute.INT64,
+		semconv.GenAIRequestStopSequencesKey:    attribute.STRINGSLICE,
+		semconv.GenAIRequestTemperatureKey:      attribute.FLOAT64,
+		semconv.GenAIRequestTopKKey:             attribute.FLOAT64,
+		semconv.GenAIRequestTopPKey:             attribute.FLOAT64,
+		semconv.GenAIResponseFinishReasonsKey:   attribute.STRINGSLICE,
+		semconv.GenAIResponseIDKey:              attribute.STRING,
+		semconv.GenAIResponseModelKey:           attribute.STRING,
+		semconv.GenAISystemInstructionsKey:      attribute.STRING,
+		semconv.GenAIToolCallIDKey:              attribute.STRING,
+		semconv.GenAIToolDescriptionKey:         attribute.STRING,
+		semconv.GenAIToolNameKey:                attribute.STRING,
+		semconv.GenAIToolTypeKey:                attribute.STRING,
+		semconv.GenAIUsageInputTokensKey:        attribute.INT64,
+		semconv.GenAIUsageOutputTokensKey:       attribute.INT64,
+		// 以下属性来自更新版本的语义约定
+		"gen_ai.tool.call.arguments":               attribute.STRING,
+		"gen_ai.tool.call.result":                  attribute.STRING,
+		"gen_ai.tool.definitions":                  attribute.STRING,
+		"gen_ai.usage.cache_read.input_tokens":     attribute.INT64,
+		"gen_ai.usage.cache_creation.input_tokens": attribute.INT64,
+	}
+)
+
+// checkedSpan 待检查span的通用表示，同时用于SDK span和OTLP span
+type checkedSpan struct {
+	name    string
+	traceID string
+	spanID  string
+	kind    trace2.SpanKind
+	failed  bool
+	attrs   map[attribute.Key]attribute.Value
+}
+
+// CheckSpan 检查已结束的span是否符合GenAI语义约定，返回所有违规
+//
+// 只有设置了gen_ai.operation.name的span需要满足操作相关的规则；其他span只检查属性命名。
+func CheckSpan(span trace.ReadOnlySpan) []Violation {
+	attrs := make(map[attribute.Key]attribute.Value, len(span.Attributes()))
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return checkGenAISpan(checkedSpan{
+		name:    span.Name(),
+		traceID: span.SpanContext().TraceID().String(),
+		spanID:  span.SpanContext().SpanID().String(),
+		kind:    span.SpanKind(),
+		failed:  span.Status().Code == codes.Error,
+		attrs:   attrs,
+	})
+}
+
+// CheckFile 检查文件导出器写入的OTLP-JSON文件中的所有span
+func CheckFile(path string) ([]Violation, error) {
+	var violations []Violation
+	err := scanOTLPJSON(path, func(rs *tracepb.ResourceSpans) error {
+		for _, ss := range rs.GetScopeSpans() {
+			for _, span := range ss.GetSpans() {
+				violations = append(violations, checkOTLPSpan(span)...)
+			}
+		}
+		return nil
+	})
+	return violations, err
+}
+
+// checkOTLPSpan 检查OTLP格式的span
+func checkOTLPSpan(span *tracepb.Span) []Violation {
+	attrs := make(map[attribute.Key]attribute.Value, len(span.GetAttributes()))
+	for _, kv := range span.GetAttributes() {
+		attrs[attribute.Key(kv.GetKey())] = otlpAttributeValue(kv.GetValue())
+	}
+	return checkGenAISpan(checkedSpan{
+		name:    span.GetName(),
+		traceID: hex.EncodeToString(span.GetTraceId()),
+		spanID:  hex.EncodeToString(span.GetSpanId()),
+		// OTLP与API的SpanKind取值一致
+		kind:   trace2.SpanKind(span.GetKind()),
+		failed: span.GetStatus().GetCode() == tracepb.Status_STATUS_CODE_ERROR,
+		attrs:  attrs,
+	})
+}
+
+// otlpAttributeValue 将OTLP属性值转换为attribute.Value，不支持的类型返回空值
+func otlpAttributeValue(v *commonpb.AnyValue) attribute.Value {
+	switch value := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return attribute.StringValue(value.StringValue)
+	case *commonpb.AnyValue_IntValue:
+		return attribute.Int64Value(value.IntValue)
+	case *commonpb.AnyValue_DoubleValue:
+		return attribute.Float64Value(value.DoubleValue)
+	case *commonpb.AnyValue_BoolValue:
+		return attribute.BoolValue(value.BoolValue)
+	case *commonpb.AnyValue_ArrayValue:
+		var values []string
+		for _, item := range value.ArrayValue.GetValues() {
+			s, ok := item.GetValue().(*commonpb.AnyValue_StringValue)
+			if !ok {
+				return attribute.Value{}
+			}
+			values = append(values, s.StringValue)
+		}
+		return attribute.StringSliceValue(values)
+	default:
+		return attribute.Value{}
+	}
+}
+
+// checkGenAISpan 按GenAI语义约定检查span
+func checkGenAISpan(span checkedSpan) []Violation {
+	var violations []Violation
+	report := func(key attribute.Key, format string, args ...interface{}) {
+		violations = append(violations, Violation{
+			SpanName:  span.name,
+			TraceID:   span.traceID,
+			SpanID:    span.spanID,
+			Attribute: string(key),
+			Message:   fmt.Sprintf(format, args...),
+		})
+	}
+
+	keys := make([]attribute.Key, 0, len(span.attrs))
+	for key := range span.attrs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	hasGenAIAttrs := false
+	for _, key := range keys {
+		value := span.attrs[key]
+		name := string(key)
+		switch {
+		case strings.HasPrefix(name, "gen_ai."):
+			hasGenAIAttrs = true
+			want, ok := genAIAttributeTypes[key]
+			if !ok {
+				if latest, deprecated := latestAttributeKey(key); deprecated {
+					report(key, "deprecated, use %s", latest)
+				} else {
+					report(key, "attribute is not defined by the GenAI semantic conventions")
+				}
+				continue
+			}
+			if value.Type() != want {
+				report(key, "expected type %s, got %s", want, value.Type())
+				continue
+			}
+		case strings.HasPrefix(name, "gen_"):
+			report(key, "unknown namespace, did you mean gen_ai.*?")
+			continue
+		default:
+			continue
+		}
+
+		if allowed, ok := enumValues[key]; ok && !containsString(allowed, value.AsString()) {
+			report(key, "unexpected value %q, expected one of %s", value.AsString(), strings.Join(allowed, ", "))
+		}
+		if (key == semconv.GenAIUsageInputTokensKey || key == semconv.GenAIUsageOutputTokensKey) && value.AsInt64() < 0 {
+			report(key, "token count must not be negative")
+		}
+	}
+
+	operation, ok := span.attrs[semconv.GenAIOperationNameKey]
+	if !ok {
+		if hasGenAIAttrs {
+			report("", "span has gen_ai.* attributes but no %s", semconv.GenAIOperationNameKey)
+		}
+		return violations
+	}
+
+	rule, ok := operationRules[operation.Emit()]
+	if !ok {
+		report(semconv.GenAIOperationNameKey, "unknown operation %q", operation.Emit())
+		return violations
+	}
+
+	for _, key := range rule.required {
+		if value, ok := span.attrs[key]; !ok || value.Emit() == "" {
+			report(key, "required for %s operations", operation.Emit())
+		}
+	}
+	if _, ok := span.attrs[semconv.ErrorTypeKey]; span.failed && !ok {
+		report(semconv.ErrorTypeKey, "required when the operation ended in an error")
+	}
+
+	wantName := operation.Emit()
+	if value, ok := span.attrs[rule.nameAttr]; ok && value.Emit() != "" {
+		wantName += " " + value.Emit()
+	}
+	if span.name != wantName {
+		report("", "span name should be %q", wantName)
+	}
+
+	if rule.kind != trace2.SpanKindUnspecified && span.kind != rule.kind {
+		report("", "span kind should be %s, got %s", rule.kind, span.kind)
+	}
+
+	return violations
+}
+
+// latestAttributeKey 返回旧版属性对应的最新属性
+func latestAttributeKey(key attribute.Key) (attribute.Key, bool) {
+	for latest, legacy := range legacyAttributeKeys {
+		if legacy == key {
+			return latest, true
+		}
+	}
+	return "", false
+}
+
+// containsString 判断values中是否包含s
+func containsString(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ConformanceProcessor 在span结束时按GenAI语义约定检查span并报告违规
+type ConformanceProcessor struct {
+	report func(Violation)
+}
+
+// NewConformanceProcessor 创建ConformanceProcessor，report为nil时将违规写入日志
+func NewConformanceProcessor(report func(Violation)) *ConformanceProcessor {
+	if report == nil {
+		report = func(v Violation) {
+			log.Printf("GenAI semconv violation: %s", v)
+		}
+	}
+	return &ConformanceProcessor{report: report}
+}
+
+// OnStart 实现trace.SpanProcessor接口
+func (p *ConformanceProcessor) OnStart(context.Context, trace.ReadWriteSpan) {}
+
+// OnEnd 实现trace.SpanProcessor接口
+func (p *ConformanceProcessor) OnEnd(s trace.ReadOnlySpan) {
+	for _, v := range CheckSpan(s) {
+		p.report(v)
+	}
+}
+
+// Shutdown 实现trace.SpanProcessor接口
+func (p *ConformanceProcessor) Shutdown(context.Context) error {
+	return nil
+}
+
+// ForceFlush 实现trace.SpanProcessor接口
+func (p *ConformanceProcessor) ForceFlush(context.Context) error {
+	return nil
+}
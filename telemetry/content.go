@@ -0,0 +1,170 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ContentCapture 定义GenAI消息内容的采集策略
+type ContentCapture string
+
+const (
+	// ContentCaptureOff 不记录消息（默认）
+	ContentCaptureOff ContentCapture = "off"
+	// ContentCaptureMetadata 只记录消息的角色等元数据，不记录内容
+	ContentCaptureMetadata ContentCapture = "metadata"
+	// ContentCaptureFull 记录经过脱敏的完整消息内容
+	ContentCaptureFull ContentCapture = "full"
+)
+
+// Redactor 在消息内容写入遥测数据之前对其脱敏
+type Redactor interface {
+	Redact(s string) string
+}
+
+// RedactorFunc 将普通函数适配为Redactor
+type RedactorFunc func(s string) string
+
+// Redact 实现Redactor接口
+func (f RedactorFunc) Redact(s string) string {
+	return f(s)
+}
+
+// redactRule 一条基于正则表达式的脱敏规则
+type redactRule struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// defaultRedactRules 默认脱敏规则，身份证号需在手机号之前匹配
+var defaultRedactRules = []redactRule{
+	{regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`), "[REDACTED_EMAIL]"},
+	{regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/\-]+=*`), "Bearer [REDACTED_API_KEY]"},
+	{regexp.MustCompile(`\b(?:sk|pk|rk)-[A-Za-z0-9_\-]{16,}\b`), "[REDACTED_API_KEY]"},
+	{regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`), "[REDACTED_API_KEY]"},
+	{regexp.MustCompile(`\b[1-9]\d{5}(?:18|19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]\b`), "[REDACTED_ID]"},
+	{regexp.MustCompile(`(?:\+?86[\s\-]?)?\b1[3-9]\d{9}\b`), "[REDACTED_PHONE]"},
+	{regexp.MustCompile(`\+\d{1,3}[\s\-]?\(?\d{1,4}\)?(?:[\s\-]?\d{2,4}){2,4}\b`), "[REDACTED_PHONE]"},
+}
+
+// DefaultRedactor 返回默认脱敏器，屏蔽邮箱、手机号、身份证号和API密钥
+func DefaultRedactor() Redactor {
+	return RedactorFunc(func(s string) string {
+		for _, rule := range defaultRedactRules {
+			s = rule.pattern.ReplaceAllString(s, rule.replacement)
+		}
+		return s
+	})
+}
+
+// contentPolicy 当前生效的内容采集策略，由Setup设置
+type contentPolicy struct {
+	capture  ContentCapture
+	redactor Redactor
+}
+
+var currentContentPolicy atomic.Pointer[contentPolicy]
+
+// setContentPolicy 设置全局内容采集策略，redactor为nil时使用DefaultRedactor
+func setContentPolicy(capture ContentCapture, redactor Redactor) {
+	if redactor == nil {
+		redactor = DefaultRedactor()
+	}
+	currentContentPolicy.Store(&contentPolicy{capture: capture, redactor: redactor})
+}
+
+// ContentCaptureMode 返回当前的内容采集策略
+func ContentCaptureMode() ContentCapture {
+	if policy := currentContentPolicy.Load(); policy != nil && policy.capture != "" {
+		return policy.capture
+	}
+	return ContentCaptureOff
+}
+
+// Redact 使用当前策略的脱敏器处理字符串，可用于消息之外的自由文本属性
+func Redact(s string) string {
+	if policy := currentContentPolicy.Load(); policy != nil {
+		return policy.redactor.Redact(s)
+	}
+	return DefaultRedactor().Redact(s)
+}
+
+// ContentAttributes 按当前策略处理消息之外的内容属性，如工具参数和结果、Agent的目标
+//
+// 这些值是自由文本，无法只保留元数据，因此只有完整采集时才返回脱敏后的属性，其他策略返回nil。
+func ContentAttributes(attrs ...attribute.KeyValue) []attribute.KeyValue {
+	if ContentCaptureMode() != ContentCaptureFull {
+		return nil
+	}
+
+	result := make([]attribute.KeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		result = append(result, attribute.String(string(kv.Key), Redact(kv.Value.Emit())))
+	}
+	return result
+}
+
+// applyContentPolicy 按当前策略处理消息属性，返回可以记录的属性
+func applyContentPolicy(messages []attribute.KeyValue) []attribute.KeyValue {
+	switch ContentCaptureMode() {
+	case ContentCaptureFull:
+		result := make([]attribute.KeyValue, 0, len(messages))
+		for _, kv := range messages {
+			result = append(result, attribute.String(string(kv.Key), Redact(kv.Value.Emit())))
+		}
+		return result
+	case ContentCaptureMetadata:
+		result := make([]attribute.KeyValue, 0, len(messages))
+		for _, kv := range messages {
+			if metadata, ok := messageMetadata(kv.Value.Emit()); ok {
+				result = append(result, attribute.String(string(kv.Key), metadata))
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// messageMetadata 从消息JSON中只保留role和finish_reason字段
+//
+// 无法解析的消息返回false，以免内容泄漏。
+func messageMetadata(raw string) (string, bool) {
+	var messages []map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(raw)), &messages); err != nil {
+		return "", false
+	}
+
+	metadata := make([]map[string]interface{}, 0, len(messages))
+	for _, message := range messages {
+		entry := make(map[string]interface{})
+		for _, key := range []string{"role", "finish_reason"} {
+			if value, ok := message[key]; ok {
+				entry[key] = value
+			}
+		}
+		metadata = append(metadata, entry)
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// parseContentCapture 解析OTEL_INSTRUMENTATION_GENAI_CAPTURE_MESSAGE_CONTENT
+func parseContentCapture(raw string) ContentCapture {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "true", "full", "span_only", "event_only", "span_and_event":
+		return ContentCaptureFull
+	case "metadata", "metadata_only":
+		return ContentCaptureMetadata
+	default:
+		return ContentCaptureOff
+	}
+}
@@ -0,0 +1,95 @@
+package telemetry
+
+import (
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// useContentPolicy 在测试期间使用指定的内容采集策略
+func useContentPolicy(t *testing.T, capture ContentCapture, redactor Redactor) {
+	t.Helper()
+
+	previous := currentContentPolicy.Load()
+	setContentPolicy(capture, redactor)
+	t.Cleanup(func() { currentContentPolicy.Store(previous) })
+}
+
+func TestContentAttributes(t *testing.T) {
+	arguments := attribute.String("gen_ai.tool.call.arguments", `{"email":"alice@example.com"}`)
+
+	tests := []struct {
+		name    string
+		capture ContentCapture
+		want    []attribute.KeyValue
+	}{
+		{name: "off", capture: ContentCaptureOff},
+		{name: "unset", capture: ""},
+		{name: "metadata", capture: ContentCaptureMetadata},
+		{
+			name:    "full is redacted",
+			capture: ContentCaptureFull,
+			want:    []attribute.KeyValue{attribute.String("gen_ai.tool.call.arguments", `{"email":"[REDACTED_EMAIL]"}`)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useContentPolicy(t, tt.capture, nil)
+			if got := ContentAttributes(arguments); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ContentAttributes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyContentPolicy(t *testing.T) {
+	messages := []attribute.KeyValue{
+		attribute.String("gen_ai.input.messages", `[{"role":"user","content":"call 13812345678"}]`),
+		attribute.String("gen_ai.output.messages", `[{"role":"assistant","content":"ok","finish_reason":"stop"}]`),
+	}
+
+	tests := []struct {
+		name     string
+		capture  ContentCapture
+		redactor Redactor
+		want     []attribute.KeyValue
+	}{
+		{name: "off", capture: ContentCaptureOff},
+		{
+			name:    "metadata keeps roles",
+			capture: ContentCaptureMetadata,
+			want: []attribute.KeyValue{
+				attribute.String("gen_ai.input.messages", `[{"role":"user"}]`),
+				attribute.String("gen_ai.output.messages", `[{"finish_reason":"stop","role":"assistant"}]`),
+			},
+		},
+		{
+			name:    "full uses default redactor",
+			capture: ContentCaptureFull,
+			want: []attribute.KeyValue{
+				attribute.String("gen_ai.input.messages", `[{"role":"user","content":"call [REDACTED_PHONE]"}]`),
+				messages[1],
+			},
+		},
+		{
+			name:     "full uses custom redactor",
+			capture:  ContentCaptureFull,
+			redactor: RedactorFunc(func(string) string { return "[]" }),
+			want: []attribute.KeyValue{
+				attribute.String("gen_ai.input.messages", "[]"),
+				attribute.String("gen_ai.output.messages", "[]"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useContentPolicy(t, tt.capture, tt.redactor)
+			if got := applyContentPolicy(messages); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyContentPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package telemetry
+
+import (
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetConfigFromEnv 从环境变量获取配置
+//
+// 遵循OpenTelemetry导出器环境变量规范：各信号专用的OTEL_EXPORTER_OTLP_{TRACES,METRICS,LOGS}_*
+// 优先于通用的OTEL_EXPORTER_OTLP_*。
+func GetConfigFromEnv() Config {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	tracesEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	metricsEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT")
+	logsEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_LOGS_ENDPOINT")
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	otlp := otlpSignalEnv("OTEL_EXPORTER_OTLP_")
+	// 跳过证书校验不在OTLP规范中，使用项目自己的前缀
+	otlp.TLS.InsecureSkipVerify = strings.EqualFold(os.Getenv("GENAI_EXAMPLE_OTLP_INSECURE_SKIP_VERIFY"), "true")
+	protocol := otlpEnv("TRACES", "PROTOCOL")
+
+	// OTEL_TRACES_EXPORTER支持逗号分隔的多个导出器，每项可用:simple或:batch指定processor
+	var exporters []ExporterSpec
+	for _, name := range strings.Split(os.Getenv("OTEL_TRACES_EXPORTER"), ",") {
+		name, processor, _ := strings.Cut(strings.TrimSpace(name), ":")
+		spec := ExporterSpec{
+			Type:      tracesExporterType(name, protocol, endpoint != "" || tracesEndpoint != ""),
+			Processor: ProcessorBatch,
+		}
+		if ProcessorType(processor) == ProcessorSimple {
+			spec.Processor = ProcessorSimple
+		}
+		exporters = append(exporters, spec)
+	}
+
+	exporterType := exporters[0].Type
+	if len(exporters) == 1 && exporters[0].Processor == ProcessorBatch {
+		exporters = nil
+	}
+
+	var metricsExporterType ExporterType
+	switch os.Getenv("OTEL_METRICS_EXPORTER") {
+	case "otlp":
+		metricsExporterType = otlpExporterType(otlpEnv("METRICS", "PROTOCOL"))
+	case "none":
+		metricsExporterType = ExporterNone
+	case "prometheus":
+		metricsExporterType = ExporterPrometheus
+	case "":
+		// 未设置时只在trace使用console导出器时输出到console
+	default:
+		metricsExporterType = ExporterType(os.Getenv("OTEL_METRICS_EXPORTER"))
+	}
+
+	var logsExporterType ExporterType
+	switch os.Getenv("OTEL_LOGS_EXPORTER") {
+	case "otlp":
+		logsExporterType = otlpExporterType(otlpEnv("LOGS", "PROTOCOL"))
+	case "none":
+		logsExporterType = ExporterNone
+	case "":
+		// 未设置时只在启用GenAI事件且trace使用console导出器时输出到console
+	default:
+		logsExporterType = ExporterType(os.Getenv("OTEL_LOGS_EXPORTER"))
+	}
+
+	return Config{
+		ExporterType:              exporterType,
+		Exporters:                 exporters,
+		Endpoint:                  endpoint,
+		TracesEndpoint:            tracesEndpoint,
+		MetricsEndpoint:           metricsEndpoint,
+		MetricsExporterType:       metricsExporterType,
+		PrometheusAddr:            prometheusAddr(),
+		LogsEndpoint:              logsEndpoint,
+		LogsExporterType:          logsExporterType,
+		EmitEvents:                strings.EqualFold(os.Getenv("OTEL_INSTRUMENTATION_GENAI_EMIT_EVENTS"), "true"),
+		FilePath:                  os.Getenv("OTEL_EXPORTER_FILE_PATH"),
+		FileMaxBytes:              parseFileMaxBytes(os.Getenv("OTEL_EXPORTER_FILE_MAX_BYTES")),
+		Sampler:                   os.Getenv("OTEL_TRACES_SAMPLER"),
+		SamplerArg:                os.Getenv("OTEL_TRACES_SAMPLER_ARG"),
+		KeepErrorTraces:           strings.EqualFold(os.Getenv("OTEL_TRACES_KEEP_ERRORS"), "true"),
+		SemconvStability:          parseSemconvStability(os.Getenv("OTEL_SEMCONV_STABILITY_OPT_IN")),
+		ValidateSemconv:           strings.EqualFold(os.Getenv("OTEL_INSTRUMENTATION_GENAI_VALIDATE_SEMCONV"), "true"),
+		AttributeValueLengthLimit: attributeValueLengthLimit(),
+		OpenInference:             strings.EqualFold(os.Getenv("OTEL_INSTRUMENTATION_GENAI_OPENINFERENCE"), "true"),
+		ContentCapture:            parseContentCapture(os.Getenv("OTEL_INSTRUMENTATION_GENAI_CAPTURE_MESSAGE_CONTENT")),
+		ServiceName:               serviceName,
+		Propagators:               parsePropagators(os.Getenv("OTEL_PROPAGATORS")),
+		Headers:                   otlp.Headers,
+		TLS:                       otlp.TLS,
+		Timeout:                   otlp.Timeout,
+		Compression:               otlp.Compression,
+		Traces:                    otlpSignalEnv("OTEL_EXPORTER_OTLP_TRACES_"),
+		Metrics:                   otlpSignalEnv("OTEL_EXPORTER_OTLP_METRICS_"),
+		Logs:                      otlpSignalEnv("OTEL_EXPORTER_OTLP_LOGS_"),
+		Disabled:                  strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true"),
+	}
+}
+
+// prometheusAddr 根据OTEL_EXPORTER_PROMETHEUS_HOST/PORT组装Prometheus监听地址，均未设置时返回空
+func prometheusAddr() string {
+	host := os.Getenv("OTEL_EXPORTER_PROMETHEUS_HOST")
+	port := os.Getenv("OTEL_EXPORTER_PROMETHEUS_PORT")
+	if host == "" && port == "" {
+		return ""
+	}
+	if host == "" {
+		host = "localhost"
+	}
+	if port == "" {
+		port = "9464"
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// tracesExporterType 将OTEL_TRACES_EXPORTER中的一项解析为导出器类型
+//
+// 设置了endpoint时，未知类型根据协议选择OTLP导出器（默认HTTP）；auto保持不变，由启动时的探测决定；
+// none表示不导出trace。
+func tracesExporterType(name, protocol string, hasEndpoint bool) ExporterType {
+	var exporterType ExporterType
+	switch name {
+	case "otlp":
+		exporterType = otlpExporterType(protocol)
+	case "http":
+		exporterType = ExporterHTTP
+	case "grpc":
+		exporterType = ExporterGRPC
+	case "file":
+		exporterType = ExporterFile
+	case "none":
+		exporterType = ExporterNone
+	case "console", "":
+		exporterType = ExporterConsole
+	default:
+		exporterType = ExporterType(name)
+	}
+
+	if hasEndpoint {
+		switch exporterType {
+		case ExporterConsole, ExporterHTTP, ExporterGRPC, ExporterFile, ExporterAuto, ExporterNone:
+		default:
+			exporterType = otlpExporterType(protocol)
+		}
+	}
+
+	return exporterType
+}
+
+// otlpEnv 读取OTLP导出器环境变量，信号专用变量（如OTEL_EXPORTER_OTLP_METRICS_PROTOCOL）优先于通用变量
+func otlpEnv(signal, name string) string {
+	if value := os.Getenv("OTEL_EXPORTER_OTLP_" + signal + "_" + name); value != "" {
+		return value
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_" + name)
+}
+
+// otlpSignalEnv 读取以prefix开头的OTLP导出器设置，如OTEL_EXPORTER_OTLP_METRICS_HEADERS
+func otlpSignalEnv(prefix string) SignalConfig {
+	return SignalConfig{
+		Headers: parseHeaders(os.Getenv(prefix + "HEADERS")),
+		TLS: TLSConfig{
+			CAFile:   os.Getenv(prefix + "CERTIFICATE"),
+			CertFile: os.Getenv(prefix + "CLIENT_CERTIFICATE"),
+			KeyFile:  os.Getenv(prefix + "CLIENT_KEY"),
+			Insecure: strings.EqualFold(os.Getenv(prefix+"INSECURE"), "true"),
+		},
+		Timeout:     parseTimeout(os.Getenv(prefix + "TIMEOUT")),
+		Compression: parseCompression(os.Getenv(prefix + "COMPRESSION")),
+	}
+}
+
+// otlpExporterType 根据OTEL_EXPORTER_OTLP_PROTOCOL选择OTLP导出器类型
+func otlpExporterType(protocol string) ExporterType {
+	if protocol == "grpc" {
+		return ExporterGRPC
+	}
+	return ExporterHTTP
+}
+
+// parseHeaders 解析OTEL_EXPORTER_OTLP_HEADERS格式的头部（key1=value1,key2=value2，值经过URL编码）
+func parseHeaders(raw string) map[string]string {
+	if raw == "" {
+		return nil
+	}
+
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			log.Printf("Ignoring invalid OTLP header: %q", pair)
+			continue
+		}
+		if decoded, err := url.PathUnescape(strings.TrimSpace(value)); err == nil {
+			value = decoded
+		}
+		headers[key] = strings.TrimSpace(value)
+	}
+
+	return headers
+}
+
+// parseTimeout 解析以毫秒为单位的超时时间
+func parseTimeout(raw string) time.Duration {
+	if raw == "" {
+		return 0
+	}
+
+	ms, err := strconv.Atoi(raw)
+	if err != nil || ms < 0 {
+		log.Printf("Ignoring invalid OTLP timeout: %q", raw)
+		return 0
+	}
+
+	return time.Duration(ms) * time.Millisecond
+}
+
+// parseFileMaxBytes 解析文件导出器的轮转大小
+func parseFileMaxBytes(raw string) int64 {
+	if raw == "" {
+		return 0
+	}
+
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || value <= 0 {
+		log.Printf("Ignoring invalid file exporter max bytes: %q", raw)
+		return 0
+	}
+
+	return value
+}
+
+// parseCompression 解析压缩方式，仅支持gzip和none
+func parseCompression(raw string) string {
+	switch raw {
+	case "gzip", "none":
+		return raw
+	case "":
+		return ""
+	default:
+		log.Printf("Ignoring unsupported OTLP compression: %q", raw)
+		return ""
+	}
+}
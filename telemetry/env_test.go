@@ -0,0 +1,423 @@
+package telemetry
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// otelEnvKeys GetConfigFromEnv读取的环境变量，每个用例开始前清空
+var otelEnvKeys = []string{
+	"OTEL_TRACES_EXPORTER",
+	"OTEL_METRICS_EXPORTER",
+	"OTEL_LOGS_EXPORTER",
+	"OTEL_EXPORTER_OTLP_ENDPOINT",
+	"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT",
+	"OTEL_EXPORTER_OTLP_PROTOCOL",
+	"OTEL_EXPORTER_OTLP_TRACES_PROTOCOL",
+	"OTEL_EXPORTER_OTLP_HEADERS",
+	"OTEL_EXPORTER_OTLP_TRACES_HEADERS",
+	"OTEL_EXPORTER_OTLP_TIMEOUT",
+	"OTEL_EXPORTER_OTLP_TRACES_TIMEOUT",
+	"OTEL_EXPORTER_OTLP_COMPRESSION",
+	"OTEL_EXPORTER_OTLP_TRACES_COMPRESSION",
+	"OTEL_EXPORTER_OTLP_METRICS_PROTOCOL",
+	"OTEL_EXPORTER_OTLP_METRICS_HEADERS",
+	"OTEL_EXPORTER_OTLP_METRICS_TIMEOUT",
+	"OTEL_EXPORTER_OTLP_METRICS_COMPRESSION",
+	"OTEL_EXPORTER_OTLP_LOGS_PROTOCOL",
+	"OTEL_EXPORTER_OTLP_LOGS_HEADERS",
+	"OTEL_EXPORTER_OTLP_LOGS_TIMEOUT",
+	"OTEL_EXPORTER_OTLP_LOGS_COMPRESSION",
+	"OTEL_SDK_DISABLED",
+}
+
+// insecureEnvKeys 与明文连接和证书校验相关的环境变量
+var insecureEnvKeys = []string{
+	"OTEL_EXPORTER_OTLP_INSECURE",
+	"OTEL_EXPORTER_OTLP_TRACES_INSECURE",
+	"OTEL_EXPORTER_OTLP_METRICS_INSECURE",
+	"OTEL_EXPORTER_OTLP_LOGS_INSECURE",
+	"OTEL_EXPORTER_OTLP_INSECURE_SKIP_VERIFY",
+	"GENAI_EXAMPLE_OTLP_INSECURE_SKIP_VERIFY",
+}
+
+// envConfig GetConfigFromEnv结果中与导出器相关的字段，OTLP设置为trace导出器实际使用的值
+type envConfig struct {
+	ExporterType   ExporterType
+	Exporters      []ExporterSpec
+	Endpoint       string
+	TracesEndpoint string
+	Headers        map[string]string
+	Timeout        time.Duration
+	Compression    string
+	Disabled       bool
+}
+
+func TestGetConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want envConfig
+	}{
+		{
+			name: "defaults to console",
+			want: envConfig{ExporterType: ExporterConsole},
+		},
+		{
+			name: "otlp defaults to http",
+			env: map[string]string{
+				"OTEL_TRACES_EXPORTER":        "otlp",
+				"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318/otlp",
+			},
+			want: envConfig{ExporterType: ExporterHTTP, Endpoint: "http://collector:4318/otlp"},
+		},
+		{
+			name: "unknown exporter with endpoint falls back to OTLP",
+			env: map[string]string{
+				"OTEL_TRACES_EXPORTER":        "zipkin",
+				"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318",
+			},
+			want: envConfig{ExporterType: ExporterHTTP, Endpoint: "http://collector:4318"},
+		},
+		{
+			name: "otlp with grpc protocol",
+			env: map[string]string{
+				"OTEL_TRACES_EXPORTER":        "otlp",
+				"OTEL_EXPORTER_OTLP_PROTOCOL": "grpc",
+			},
+			want: envConfig{ExporterType: ExporterGRPC},
+		},
+		{
+			name: "traces protocol overrides generic protocol",
+			env: map[string]string{
+				"OTEL_TRACES_EXPORTER":               "otlp",
+				"OTEL_EXPORTER_OTLP_PROTOCOL":        "http/protobuf",
+				"OTEL_EXPORTER_OTLP_TRACES_PROTOCOL": "grpc",
+			},
+			want: envConfig{ExporterType: ExporterGRPC},
+		},
+		{
+			name: "traces endpoint",
+			env: map[string]string{
+				"OTEL_TRACES_EXPORTER":               "http",
+				"OTEL_EXPORTER_OTLP_ENDPOINT":        "http://collector:4318",
+				"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "https://collector/custom/traces",
+			},
+			want: envConfig{
+				ExporterType:   ExporterHTTP,
+				Endpoint:       "http://collector:4318",
+				TracesEndpoint: "https://collector/custom/traces",
+			},
+		},
+		{
+			name: "none disables traces",
+			env:  map[string]string{"OTEL_TRACES_EXPORTER": "none"},
+			want: envConfig{ExporterType: ExporterNone},
+		},
+		{
+			name: "none is kept when an endpoint is set",
+			env: map[string]string{
+				"OTEL_TRACES_EXPORTER":        "none",
+				"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318",
+			},
+			want: envConfig{ExporterType: ExporterNone, Endpoint: "http://collector:4318"},
+		},
+		{
+			name: "multiple exporters with processors",
+			env:  map[string]string{"OTEL_TRACES_EXPORTER": "console:simple, otlp"},
+			want: envConfig{
+				ExporterType: ExporterConsole,
+				Exporters: []ExporterSpec{
+					{Type: ExporterConsole, Processor: ProcessorSimple},
+					{Type: ExporterHTTP, Processor: ProcessorBatch},
+				},
+			},
+		},
+		{
+			name: "single exporter with simple processor",
+			env:  map[string]string{"OTEL_TRACES_EXPORTER": "file:simple"},
+			want: envConfig{
+				ExporterType: ExporterFile,
+				Exporters:    []ExporterSpec{{Type: ExporterFile, Processor: ProcessorSimple}},
+			},
+		},
+		{
+			name: "traces-specific variables take precedence",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_HEADERS":            "Authorization=Bearer%20generic",
+				"OTEL_EXPORTER_OTLP_TRACES_HEADERS":     "Authorization=Bearer%20traces,X-Scope-OrgID=tenant-1",
+				"OTEL_EXPORTER_OTLP_TIMEOUT":            "1000",
+				"OTEL_EXPORTER_OTLP_TRACES_TIMEOUT":     "2500",
+				"OTEL_EXPORTER_OTLP_COMPRESSION":        "none",
+				"OTEL_EXPORTER_OTLP_TRACES_COMPRESSION": "gzip",
+			},
+			want: envConfig{
+				ExporterType: ExporterConsole,
+				Headers:      map[string]string{"Authorization": "Bearer traces", "X-Scope-OrgID": "tenant-1"},
+				Timeout:      2500 * time.Millisecond,
+				Compression:  "gzip",
+			},
+		},
+		{
+			name: "invalid values are ignored",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_HEADERS":     "missing-separator",
+				"OTEL_EXPORTER_OTLP_TIMEOUT":     "soon",
+				"OTEL_EXPORTER_OTLP_COMPRESSION": "zstd",
+			},
+			want: envConfig{ExporterType: ExporterConsole, Headers: map[string]string{}},
+		},
+		{
+			name: "sdk disabled",
+			env:  map[string]string{"OTEL_SDK_DISABLED": "TRUE"},
+			want: envConfig{ExporterType: ExporterConsole, Disabled: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range otelEnvKeys {
+				t.Setenv(key, "")
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			config := GetConfigFromEnv()
+			traces := config.forSignal(config.Traces)
+			got := envConfig{
+				ExporterType:   config.ExporterType,
+				Exporters:      config.Exporters,
+				Endpoint:       config.Endpoint,
+				TracesEndpoint: config.TracesEndpoint,
+				Headers:        traces.Headers,
+				Timeout:        traces.Timeout,
+				Compression:    traces.Compression,
+				Disabled:       config.Disabled,
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetConfigFromEnv() = %+v, want %+v", got, tt.want)
+			}
+			if err := config.Validate(); err != nil {
+				t.Errorf("Validate() = %v", err)
+			}
+		})
+	}
+}
+
+func TestSignalConfigFromEnv(t *testing.T) {
+	for _, key := range otelEnvKeys {
+		t.Setenv(key, "")
+	}
+	env := map[string]string{
+		"OTEL_TRACES_EXPORTER":                  "otlp",
+		"OTEL_METRICS_EXPORTER":                 "otlp",
+		"OTEL_LOGS_EXPORTER":                    "otlp",
+		"OTEL_EXPORTER_OTLP_PROTOCOL":           "http/protobuf",
+		"OTEL_EXPORTER_OTLP_HEADERS":            "Authorization=Bearer%20generic",
+		"OTEL_EXPORTER_OTLP_TIMEOUT":            "1000",
+		"OTEL_EXPORTER_OTLP_TRACES_HEADERS":     "Authorization=Bearer%20traces",
+		"OTEL_EXPORTER_OTLP_METRICS_PROTOCOL":   "grpc",
+		"OTEL_EXPORTER_OTLP_METRICS_HEADERS":    "Authorization=Bearer%20metrics",
+		"OTEL_EXPORTER_OTLP_METRICS_TIMEOUT":    "3000",
+		"OTEL_EXPORTER_OTLP_LOGS_COMPRESSION":   "gzip",
+		"OTEL_EXPORTER_OTLP_LOGS_CERTIFICATE":   "/etc/otel/logs-ca.pem",
+		"OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE": "/etc/otel/client.pem",
+		"OTEL_EXPORTER_OTLP_CLIENT_KEY":         "/etc/otel/client-key.pem",
+		"OTEL_EXPORTER_OTLP_CERTIFICATE":        "/etc/otel/ca.pem",
+		"OTEL_EXPORTER_OTLP_TRACES_CLIENT_KEY":  "/etc/otel/traces-key.pem",
+		"OTEL_EXPORTER_OTLP_TRACES_COMPRESSION": "none",
+	}
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+
+	config := GetConfigFromEnv()
+	if config.ExporterType != ExporterHTTP || config.MetricsExporterType != ExporterGRPC || config.LogsExporterType != ExporterHTTP {
+		t.Errorf("exporter types = %s/%s/%s, want http/grpc/http", config.ExporterType, config.MetricsExporterType, config.LogsExporterType)
+	}
+
+	tests := []struct {
+		name   string
+		signal SignalConfig
+		want   SignalConfig
+	}{
+		{
+			name:   "traces",
+			signal: config.Traces,
+			want: SignalConfig{
+				Headers:     map[string]string{"Authorization": "Bearer traces"},
+				TLS:         TLSConfig{CAFile: "/etc/otel/ca.pem", KeyFile: "/etc/otel/traces-key.pem"},
+				Timeout:     time.Second,
+				Compression: "none",
+			},
+		},
+		{
+			name:   "metrics",
+			signal: config.Metrics,
+			want: SignalConfig{
+				Headers: map[string]string{"Authorization": "Bearer metrics"},
+				TLS:     TLSConfig{CAFile: "/etc/otel/ca.pem", CertFile: "/etc/otel/client.pem", KeyFile: "/etc/otel/client-key.pem"},
+				Timeout: 3 * time.Second,
+			},
+		},
+		{
+			name:   "logs",
+			signal: config.Logs,
+			want: SignalConfig{
+				Headers:     map[string]string{"Authorization": "Bearer generic"},
+				TLS:         TLSConfig{CAFile: "/etc/otel/logs-ca.pem", CertFile: "/etc/otel/client.pem", KeyFile: "/etc/otel/client-key.pem"},
+				Timeout:     time.Second,
+				Compression: "gzip",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			effective := config.forSignal(tt.signal)
+			got := SignalConfig{
+				Headers:     effective.Headers,
+				TLS:         effective.TLS,
+				Timeout:     effective.Timeout,
+				Compression: effective.Compression,
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s config = %+v, want %+v", tt.name, got, tt.want)
+			}
+		})
+	}
+
+	// 只设置了私钥的trace专用配置无法与通用证书组合
+	err := config.Validate()
+	if err == nil || !strings.Contains(err.Error(), "traces client certificate and key must be set together") {
+		t.Errorf("Validate() = %v, want the traces client certificate error", err)
+	}
+}
+
+func TestTracesEndpoint(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   otlpEndpoint
+	}{
+		{
+			name:   "appends signal path",
+			config: Config{Endpoint: "http://collector:4318"},
+			want:   otlpEndpoint{host: "collector:4318", path: "/v1/traces"},
+		},
+		{
+			name:   "preserves reverse proxy prefix",
+			config: Config{Endpoint: "https://gateway:8443/otlp/"},
+			want:   otlpEndpoint{host: "gateway:8443", path: "/otlp/v1/traces", secure: true},
+		},
+		{
+			name:   "endpoint without scheme",
+			config: Config{Endpoint: "localhost:4317"},
+			want:   otlpEndpoint{host: "localhost:4317", path: "/v1/traces", noScheme: true},
+		},
+		{
+			name:   "traces endpoint is used as is",
+			config: Config{Endpoint: "http://collector:4318", TracesEndpoint: "https://traces.example.com/custom"},
+			want:   otlpEndpoint{host: "traces.example.com", path: "/custom", secure: true},
+		},
+		{
+			name:   "traces endpoint without path",
+			config: Config{TracesEndpoint: "http://collector:4318"},
+			want:   otlpEndpoint{host: "collector:4318", path: "/"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.tracesEndpoint()
+			if err != nil {
+				t.Fatalf("tracesEndpoint() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("tracesEndpoint() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGRPCSecure(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		insecure bool
+		want     bool
+	}{
+		{name: "https scheme", endpoint: "https://collector:4317", want: true},
+		{name: "http scheme", endpoint: "http://collector:4317", want: false},
+		{name: "https scheme ignores insecure", endpoint: "https://collector:4317", insecure: true, want: true},
+		{name: "no scheme defaults to TLS", endpoint: "collector:4317", want: true},
+		{name: "no scheme with insecure", endpoint: "collector:4317", insecure: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{Endpoint: tt.endpoint, TLS: TLSConfig{Insecure: tt.insecure}}
+			endpoint, err := config.tracesEndpoint()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := config.grpcSecure(endpoint); got != tt.want {
+				t.Errorf("grpcSecure() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsecureFromEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		// want 依次为通用、trace、metric和日志的TLS配置
+		want [4]TLSConfig
+	}{
+		{name: "unset"},
+		{
+			name: "generic insecure",
+			env:  map[string]string{"OTEL_EXPORTER_OTLP_INSECURE": "true"},
+			want: [4]TLSConfig{{Insecure: true}},
+		},
+		{
+			name: "signal insecure",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_TRACES_INSECURE":  "TRUE",
+				"OTEL_EXPORTER_OTLP_METRICS_INSECURE": "false",
+				"OTEL_EXPORTER_OTLP_LOGS_INSECURE":    "true",
+			},
+			want: [4]TLSConfig{{}, {Insecure: true}, {}, {Insecure: true}},
+		},
+		{
+			name: "project skip verify",
+			env:  map[string]string{"GENAI_EXAMPLE_OTLP_INSECURE_SKIP_VERIFY": "true"},
+			want: [4]TLSConfig{{InsecureSkipVerify: true}},
+		},
+		{
+			name: "OTEL-prefixed skip verify is not read",
+			env:  map[string]string{"OTEL_EXPORTER_OTLP_INSECURE_SKIP_VERIFY": "true"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range insecureEnvKeys {
+				t.Setenv(key, "")
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			config := GetConfigFromEnv()
+			got := [4]TLSConfig{config.TLS, config.Traces.TLS, config.Metrics.TLS, config.Logs.TLS}
+			if got != tt.want {
+				t.Errorf("TLS configs = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
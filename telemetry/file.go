@@ -0,0 +1,240 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/sdk/trace"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	// DefaultFilePath 文件导出器默认输出路径
+	DefaultFilePath = "traces.jsonl"
+	// DefaultFileMaxBytes 文件导出器默认的轮转大小（100MB）
+	DefaultFileMaxBytes = 100 << 20
+)
+
+// otlpIDKeys OTLP/JSON中需要以十六进制编码的ID字段
+var otlpIDKeys = map[string]bool{
+	"traceId":      true,
+	"spanId":       true,
+	"parentSpanId": true,
+}
+
+// createFileExporter 创建文件导出器，每行写入一个OTLP-JSON格式的ResourceSpans
+func createFileExporter(ctx context.Context, config Config) (trace.SpanExporter, error) {
+	path := config.FilePath
+	if path == "" {
+		path = DefaultFilePath
+	}
+	maxBytes := config.FileMaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultFileMaxBytes
+	}
+
+	return otlptrace.New(ctx, &fileClient{
+		path:     path,
+		maxBytes: maxBytes,
+	})
+}
+
+// fileClient 将OTLP数据写入本地文件的otlptrace.Client实现
+type fileClient struct {
+	path     string
+	maxBytes int64
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// Start 实现otlptrace.Client接口
+func (c *fileClient) Start(_ context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.open()
+}
+
+// Stop 实现otlptrace.Client接口
+func (c *fileClient) Stop(_ context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.file == nil {
+		return nil
+	}
+	err := c.file.Close()
+	c.file = nil
+	return err
+}
+
+// UploadTraces 实现otlptrace.Client接口
+func (c *fileClient) UploadTraces(_ context.Context, protoSpans []*tracepb.ResourceSpans) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.file == nil {
+		return fmt.Errorf("file exporter is stopped")
+	}
+
+	for _, rs := range protoSpans {
+		line, err := MarshalOTLPJSON(rs)
+		if err != nil {
+			return err
+		}
+		line = append(line, '\n')
+
+		if c.size > 0 && c.size+int64(len(line)) > c.maxBytes {
+			if err := c.rotate(); err != nil {
+				return err
+			}
+		}
+
+		n, err := c.file.Write(line)
+		c.size += int64(n)
+		if err != nil {
+			return fmt.Errorf("failed to write trace file: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// open 以追加方式打开输出文件
+func (c *fileClient) open() error {
+	file, err := os.OpenFile(c.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open trace file: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat trace file: %v", err)
+	}
+
+	c.file = file
+	c.size = info.Size()
+	return nil
+}
+
+// rotate 将当前文件重命名为带时间戳的文件，并重新打开新的输出文件
+func (c *fileClient) rotate() error {
+	if err := c.file.Close(); err != nil {
+		return fmt.Errorf("failed to close trace file: %v", err)
+	}
+	c.file = nil
+
+	// 同一时间戳内多次轮转时追加序号，避免覆盖已轮转的文件
+	base := fmt.Sprintf("%s.%s", c.path, time.Now().Format("20060102T150405.000000"))
+	rotated := base
+	for i := 1; fileExists(rotated); i++ {
+		rotated = fmt.Sprintf("%s.%d", base, i)
+	}
+	if err := os.Rename(c.path, rotated); err != nil {
+		return fmt.Errorf("failed to rotate trace file: %v", err)
+	}
+
+	return c.open()
+}
+
+// fileExists 判断路径是否已存在
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// MarshalOTLPJSON 将ResourceSpans编码为单行OTLP/JSON
+//
+// 与protojson默认输出不同，OTLP/JSON要求traceId/spanId使用十六进制编码，枚举使用整数值。
+func MarshalOTLPJSON(rs *tracepb.ResourceSpans) ([]byte, error) {
+	data, err := protojson.MarshalOptions{UseEnumNumbers: true}.Marshal(rs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal resource spans: %v", err)
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode resource spans: %v", err)
+	}
+	if err := convertOTLPIDs(doc, base64ToHex); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(doc)
+}
+
+// UnmarshalOTLPJSON 解析一行OTLP/JSON格式的ResourceSpans
+func UnmarshalOTLPJSON(line []byte) (*tracepb.ResourceSpans, error) {
+	var doc interface{}
+	if err := json.Unmarshal(line, &doc); err != nil {
+		return nil, fmt.Errorf("invalid OTLP/JSON: %v", err)
+	}
+	if err := convertOTLPIDs(doc, hexToBase64); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	rs := &tracepb.ResourceSpans{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, rs); err != nil {
+		return nil, fmt.Errorf("invalid OTLP/JSON: %v", err)
+	}
+	return rs, nil
+}
+
+// convertOTLPIDs 递归转换文档中ID字段的编码
+func convertOTLPIDs(doc interface{}, convert func(string) (string, error)) error {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if s, ok := value.(string); ok && otlpIDKeys[key] {
+				converted, err := convert(s)
+				if err != nil {
+					return fmt.Errorf("invalid %s %q: %v", key, s, err)
+				}
+				v[key] = converted
+				continue
+			}
+			if err := convertOTLPIDs(value, convert); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, value := range v {
+			if err := convertOTLPIDs(value, convert); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// base64ToHex 将protojson输出的base64 ID转换为十六进制
+func base64ToHex(s string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
+
+// hexToBase64 将十六进制ID转换为protojson可解析的base64
+func hexToBase64(s string) (string, error) {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
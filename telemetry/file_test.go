@@ -0,0 +1,113 @@
+package telemetry
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	trace2 "go.opentelemetry.io/otel/trace"
+)
+
+// hexID 匹配OTLP/JSON中十六进制编码的ID
+var hexID = regexp.MustCompile(`^[0-9a-f]+$`)
+
+// readOTLPLines 读取目录中所有文件的行，以文件名为键
+func readOTLPLines(t *testing.T, dir string) map[string][]string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string][]string)
+	for _, entry := range entries {
+		file, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			files[entry.Name()] = append(files[entry.Name()], scanner.Text())
+		}
+		file.Close()
+		if err := scanner.Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return files
+}
+
+func TestFileExporter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "traces.jsonl")
+
+	// 上限小于一行，每次导出都会轮转出一个新文件
+	exporter, err := createFileExporter(context.Background(), Config{FilePath: path, FileMaxBytes: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{"first", "second", "third"}
+	for i, name := range names {
+		spans := tracetest.SpanStubs{{
+			Name: name,
+			SpanContext: trace2.NewSpanContext(trace2.SpanContextConfig{
+				TraceID:    trace2.TraceID{byte(i + 1)},
+				SpanID:     trace2.SpanID{byte(i + 1)},
+				TraceFlags: trace2.FlagsSampled,
+			}),
+		}}.Snapshots()
+		if err := exporter.ExportSpans(context.Background(), spans); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	files := readOTLPLines(t, dir)
+	if len(files) != len(names) {
+		t.Fatalf("found %d files, want %d after rotation: %v", len(files), len(names), files)
+	}
+	if lines := files["traces.jsonl"]; len(lines) != 1 || !strings.Contains(lines[0], `"third"`) {
+		t.Errorf("current file = %v, want the last span", lines)
+	}
+
+	var exported []string
+	for name, lines := range files {
+		if name != "traces.jsonl" && !strings.HasPrefix(name, "traces.jsonl.") {
+			t.Errorf("unexpected rotated file name %s", name)
+		}
+		for _, line := range lines {
+			var doc struct {
+				ScopeSpans []struct {
+					Spans []struct {
+						TraceID string `json:"traceId"`
+						SpanID  string `json:"spanId"`
+						Name    string `json:"name"`
+					} `json:"spans"`
+				} `json:"scopeSpans"`
+			}
+			if err := json.Unmarshal([]byte(line), &doc); err != nil {
+				t.Fatalf("line in %s is not JSON: %v", name, err)
+			}
+			span := doc.ScopeSpans[0].Spans[0]
+			if len(span.TraceID) != 32 || !hexID.MatchString(span.TraceID) || len(span.SpanID) != 16 || !hexID.MatchString(span.SpanID) {
+				t.Errorf("IDs are not hex encoded: traceId=%s spanId=%s", span.TraceID, span.SpanID)
+			}
+			if _, err := UnmarshalOTLPJSON([]byte(line)); err != nil {
+				t.Errorf("UnmarshalOTLPJSON() error: %v", err)
+			}
+			exported = append(exported, span.Name)
+		}
+	}
+	if len(exported) != len(names) {
+		t.Errorf("exported spans = %v, want %v", exported, names)
+	}
+}
@@ -0,0 +1,235 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// truncationMarker 截断后追加的标记
+const truncationMarker = "...[truncated]"
+
+// attributeValueLengthLimit 从环境变量读取属性值长度上限，span专用的设置优先，0表示不限制
+func attributeValueLengthLimit() int {
+	for _, key := range []string{"OTEL_SPAN_ATTRIBUTE_VALUE_LENGTH_LIMIT", "OTEL_ATTRIBUTE_VALUE_LENGTH_LIMIT"} {
+		raw := os.Getenv(key)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			log.Printf("Ignoring invalid %s: %q", key, raw)
+			continue
+		}
+		return value
+	}
+	return 0
+}
+
+// limitExporter 在导出前按长度上限截断字符串属性
+//
+// SDK自带的截断会从中间切断消息JSON，因此由该导出器代替SDK截断。
+type limitExporter struct {
+	next  trace.SpanExporter
+	limit int
+}
+
+// newLimitExporter 创建截断属性的导出器，limit不大于0时直接返回next
+func newLimitExporter(next trace.SpanExporter, limit int) trace.SpanExporter {
+	if limit <= 0 {
+		return next
+	}
+	return &limitExporter{next: next, limit: limit}
+}
+
+// ExportSpans 实现trace.SpanExporter接口
+func (e *limitExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	truncated := make([]trace.ReadOnlySpan, len(spans))
+	for i, span := range spans {
+		truncated[i] = translatedSpan{
+			ReadOnlySpan: span,
+			attrs:        truncateAttributes(span.Attributes(), e.limit),
+		}
+	}
+	return e.next.ExportSpans(ctx, truncated)
+}
+
+// Shutdown 实现trace.SpanExporter接口
+func (e *limitExporter) Shutdown(ctx context.Context) error {
+	return e.next.Shutdown(ctx)
+}
+
+// truncateAttributes 截断超过上限的字符串属性，未超限时返回原切片
+func truncateAttributes(attrs []attribute.KeyValue, limit int) []attribute.KeyValue {
+	var result []attribute.KeyValue
+	for i, kv := range attrs {
+		var value attribute.Value
+		switch kv.Value.Type() {
+		case attribute.STRING:
+			s := kv.Value.AsString()
+			if utf8.RuneCountInString(s) <= limit {
+				continue
+			}
+			value = attribute.StringValue(truncateValue(s, limit))
+		case attribute.STRINGSLICE:
+			values := kv.Value.AsStringSlice()
+			changed := false
+			for j, s := range values {
+				if utf8.RuneCountInString(s) > limit {
+					values[j] = truncateValue(s, limit)
+					changed = true
+				}
+			}
+			if !changed {
+				continue
+			}
+			value = attribute.StringSliceValue(values)
+		default:
+			continue
+		}
+
+		if result == nil {
+			result = make([]attribute.KeyValue, len(attrs))
+			copy(result, attrs)
+		}
+		result[i] = attribute.KeyValue{Key: kv.Key, Value: value}
+	}
+	if result == nil {
+		return attrs
+	}
+	return result
+}
+
+// truncateValue 将字符串截断到limit个字符以内
+//
+// JSON格式的值（如gen_ai.input.messages）只截断其中的各个字符串字段并追加标记，
+// 截断后仍是合法的JSON（对象的字段按名称重新排序）；无法容纳时返回jsonPlaceholder。
+// 不是合法JSON的值按普通字符串截断。
+func truncateValue(s string, limit int) string {
+	if len(s) > 0 && (s[0] == '[' || s[0] == '{') && json.Valid([]byte(s)) {
+		if truncated, ok := truncateJSON(s, limit); ok {
+			return truncated
+		}
+		return jsonPlaceholder(s[0], limit)
+	}
+	return truncateString(s, limit)
+}
+
+// truncateString 按字符截断并追加标记，结果不超过limit个字符
+func truncateString(s string, limit int) string {
+	markerLen := utf8.RuneCountInString(truncationMarker)
+	if limit <= markerLen {
+		return string([]rune(s)[:limit])
+	}
+	return string([]rune(s)[:limit-markerLen]) + truncationMarker
+}
+
+// jsonPlaceholder 返回与原值类型相同、不超过limit个字符的JSON占位值
+//
+// 优先使用带truncated标记的值，limit小于2时无法容纳合法JSON，返回空字符串。
+func jsonPlaceholder(open byte, limit int) string {
+	candidates := []string{`{"truncated":true}`, `{}`}
+	if open == '[' {
+		candidates = []string{`[{"truncated":true}]`, `[]`}
+	}
+	for _, candidate := range candidates {
+		if len(candidate) <= limit {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// truncateJSON 截断JSON中的字符串字段，使编码后的结果不超过limit个字符
+//
+// 即使所有字符串都截断后仍超限时，顶层数组（如消息列表）从末尾丢弃元素，
+// 并在末尾追加{"truncated":N}元素记录丢弃的数量。
+func truncateJSON(s string, limit int) (string, bool) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(s)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return "", false
+	}
+
+	maxLen := utf8.RuneCountInString(s)
+	if truncated, ok := fitJSON(value, limit, maxLen); ok {
+		return truncated, true
+	}
+	items, isArray := value.([]interface{})
+	if !isArray {
+		return "", false
+	}
+	for kept := len(items) - 1; kept >= 0; kept-- {
+		candidate := append(items[:kept:kept], map[string]interface{}{"truncated": len(items) - kept})
+		if truncated, ok := fitJSON(candidate, limit, maxLen); ok {
+			return truncated, true
+		}
+	}
+	return "", false
+}
+
+// fitJSON 二分查找字符串字段的最大长度，使编码后的结果不超过limit个字符
+func fitJSON(value interface{}, limit, maxLen int) (string, bool) {
+	best, ok := "", false
+	low, high := 0, maxLen
+	for low <= high {
+		mid := (low + high) / 2
+		encoded, err := encodeJSON(truncateJSONStrings(value, mid))
+		if err != nil {
+			return "", false
+		}
+		if utf8.RuneCountInString(encoded) <= limit {
+			best, ok = encoded, true
+			low = mid + 1
+		} else {
+			high = mid - 1
+		}
+	}
+	return best, ok
+}
+
+// truncateJSONStrings 返回将超过maxLen个字符的字符串截断后的副本
+//
+// 只截断加上标记后确实变短的字符串。
+func truncateJSONStrings(value interface{}, maxLen int) interface{} {
+	switch v := value.(type) {
+	case string:
+		if utf8.RuneCountInString(v) > maxLen+utf8.RuneCountInString(truncationMarker) {
+			return string([]rune(v)[:maxLen]) + truncationMarker
+		}
+		return v
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = truncateJSONStrings(item, maxLen)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = truncateJSONStrings(item, maxLen)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// encodeJSON 编码JSON，不转义HTML字符，以免结果变长
+func encodeJSON(value interface{}) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))), nil
+}
@@ -0,0 +1,201 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTruncateValue(t *testing.T) {
+	long := strings.Repeat("很长的内容", 20)
+	twoMessages := `[{"role":"user","content":"` + long + `"},{"role":"assistant","content":"` + long + `"}]`
+
+	tests := []struct {
+		name  string
+		value string
+		limit int
+		want  string
+		// check 在want为空时校验结果
+		check func(t *testing.T, got string)
+	}{
+		{name: "plain string", value: "abcdefghijklmnopqrstuvwxyz", limit: 20, want: "abcdef...[truncated]"},
+		{name: "multibyte runes", value: strings.Repeat("你好世界", 5), limit: 16, want: "你好...[truncated]"},
+		{name: "limit equals marker length", value: "abcdefghijklmnopqrstuvwxyz", limit: 14, want: "abcdefghijklmn"},
+		{name: "limit below marker length", value: "你好世界你好世界", limit: 3, want: "你好世"},
+		{name: "invalid JSON is a plain string", value: `[{"role":"user"` + strings.Repeat("x", 30), limit: 20, want: `[{"rol...[truncated]`},
+		{
+			name:  "nested JSON keeps structure",
+			value: `{"messages":[{"role":"user","parts":[{"type":"text","content":"` + long + `"}]}],"count":1}`,
+			limit: 120,
+			check: func(t *testing.T, got string) {
+				var decoded struct {
+					Messages []struct {
+						Role  string `json:"role"`
+						Parts []struct {
+							Content string `json:"content"`
+						} `json:"parts"`
+					} `json:"messages"`
+					Count int `json:"count"`
+				}
+				if err := json.Unmarshal([]byte(got), &decoded); err != nil {
+					t.Fatal(err)
+				}
+				if decoded.Count != 1 || len(decoded.Messages) != 1 || decoded.Messages[0].Role != "user" {
+					t.Errorf("structure changed: %s", got)
+				}
+				if content := decoded.Messages[0].Parts[0].Content; !strings.HasSuffix(content, truncationMarker) {
+					t.Errorf("content %q is not marked as truncated", content)
+				}
+			},
+		},
+		{
+			name:  "strings in messages are truncated first",
+			value: twoMessages,
+			limit: 150,
+			check: func(t *testing.T, got string) {
+				var messages []map[string]interface{}
+				if err := json.Unmarshal([]byte(got), &messages); err != nil {
+					t.Fatal(err)
+				}
+				if len(messages) != 2 || messages[1]["role"] != "assistant" {
+					t.Errorf("messages were dropped: %s", got)
+				}
+			},
+		},
+		{
+			name:  "dropped array elements are marked",
+			value: twoMessages,
+			limit: 70,
+			check: func(t *testing.T, got string) {
+				var messages []map[string]interface{}
+				if err := json.Unmarshal([]byte(got), &messages); err != nil {
+					t.Fatal(err)
+				}
+				if len(messages) != 2 || messages[0]["role"] != "user" || messages[1]["truncated"] != 1.0 {
+					t.Errorf("got %s, want the user message and a truncated marker", got)
+				}
+			},
+		},
+		{name: "only the marker element fits", value: `[{"role":"user","content":"x"}]`, limit: 20, want: `[{"truncated":1}]`},
+		{name: "object fallback", value: `{"a":1}`, limit: 3, want: `{}`},
+		{name: "object fallback with marker", value: `{"key":"` + long + `"}`, limit: 18, want: `{"truncated":true}`},
+		{name: "array fallback", value: `[{"role":"user","content":"x"}]`, limit: 5, want: `[]`},
+		{name: "no room for JSON", value: `{"a":1}`, limit: 1, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateValue(tt.value, tt.limit)
+			if n := utf8.RuneCountInString(got); n > tt.limit {
+				t.Errorf("result has %d characters, limit %d: %s", n, tt.limit, got)
+			}
+			if json.Valid([]byte(tt.value)) && tt.limit >= 2 && !json.Valid([]byte(got)) {
+				t.Errorf("result is not valid JSON: %s", got)
+			}
+			if tt.check != nil {
+				tt.check(t, got)
+			} else if got != tt.want {
+				t.Errorf("truncateValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateAttributes(t *testing.T) {
+	attrs := []attribute.KeyValue{
+		attribute.String("short", "ok"),
+		attribute.String("long", strings.Repeat("a", 30)),
+		attribute.StringSlice("slice", []string{"ok", strings.Repeat("b", 30)}),
+		attribute.Int("number", 1234567890),
+	}
+
+	got := truncateAttributes(attrs, 20)
+	want := []attribute.KeyValue{
+		attrs[0],
+		attribute.String("long", "aaaaaa...[truncated]"),
+		attribute.StringSlice("slice", []string{"ok", "bbbbbb...[truncated]"}),
+		attrs[3],
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("attribute %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if attrs[1].Value.AsString() != strings.Repeat("a", 30) {
+		t.Error("original attributes were modified")
+	}
+
+	unchanged := attrs[:1]
+	if got := truncateAttributes(unchanged, 20); &got[0] != &unchanged[0] {
+		t.Error("expected the original slice when nothing is truncated")
+	}
+}
+
+func TestNewLimitExporter(t *testing.T) {
+	recorder := tracetest.NewInMemoryExporter()
+	if newLimitExporter(recorder, 0) != recorder {
+		t.Error("expected no wrapper without a limit")
+	}
+
+	messages := `[{"role":"user","content":"` + strings.Repeat("x", 100) + `"}]`
+	exporter := newLimitExporter(recorder, 60)
+	spans := tracetest.SpanStubs{{
+		Name:       "chat",
+		Attributes: []attribute.KeyValue{attribute.String("gen_ai.input.messages", messages)},
+	}}.Snapshots()
+	if err := exporter.ExportSpans(context.Background(), spans); err != nil {
+		t.Fatal(err)
+	}
+
+	exported := recorder.GetSpans()
+	if len(exported) != 1 {
+		t.Fatalf("exported %d spans, want 1", len(exported))
+	}
+	got := exported[0].Attributes[0].Value.AsString()
+	if utf8.RuneCountInString(got) > 60 || !json.Valid([]byte(got)) || !strings.Contains(got, truncationMarker) {
+		t.Errorf("gen_ai.input.messages = %s", got)
+	}
+}
+
+func TestSetupAttributeValueLengthLimit(t *testing.T) {
+	t.Setenv("OTEL_SPAN_ATTRIBUTE_VALUE_LENGTH_LIMIT", "5")
+
+	tests := []struct {
+		name  string
+		limit int
+		want  string
+	}{
+		{name: "SDK limit from env when Config has none", want: `"abcde"`},
+		{name: "exporter limit replaces SDK limit", limit: 20, want: `"abcdef...[truncated]"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "traces.jsonl")
+			setupForTest(t, Config{
+				Exporters:                 []ExporterSpec{{Type: ExporterFile, Processor: ProcessorSimple}},
+				FilePath:                  path,
+				AttributeValueLengthLimit: tt.limit,
+			})
+
+			_, span := GetTracer("test").Start(context.Background(), "limit")
+			span.SetAttributes(attribute.String("value", "abcdefghijklmnopqrstuvwxyz"))
+			span.End()
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(data), tt.want) {
+				t.Errorf("exported span does not contain %s: %s", tt.want, data)
+			}
+		})
+	}
+}
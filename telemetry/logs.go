@@ -0,0 +1,307 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	trace2 "go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	// InferenceDetailsEventName GenAI推理操作详情事件名称
+	InferenceDetailsEventName = "gen_ai.client.inference.operation.details"
+	// ChoiceEventName 模型输出的每条消息对应的事件名称
+	ChoiceEventName = "gen_ai.choice"
+)
+
+// messageEventNames 输入消息的角色对应的事件名称
+var messageEventNames = map[string]string{
+	"system":    "gen_ai.system.message",
+	"user":      "gen_ai.user.message",
+	"assistant": "gen_ai.assistant.message",
+	"tool":      "gen_ai.tool.message",
+}
+
+// emitEvents 是否以日志事件记录GenAI消息内容，由Setup设置
+var emitEvents atomic.Bool
+
+// logsExporterType 返回日志导出器类型
+//
+// 未设置LogsExporterType时只在启用GenAI事件且trace使用console导出器时输出到console，否则不导出，
+// 以免向只接收trace的后端（如Tempo）推送日志。
+func (c Config) logsExporterType() ExporterType {
+	if c.LogsExporterType != "" {
+		return c.LogsExporterType
+	}
+	if c.EmitEvents && (c.ExporterType == ExporterConsole || c.ExporterType == "") {
+		return ExporterConsole
+	}
+	return ExporterNone
+}
+
+// newLoggerProvider 根据配置创建logger provider，日志导出被禁用时返回nil
+func newLoggerProvider(ctx context.Context, config Config, res *resource.Resource) (*sdklog.LoggerProvider, error) {
+	exporterType := config.logsExporterType()
+
+	var exporter sdklog.Exporter
+	var err error
+
+	switch exporterType {
+	case ExporterNone, ExporterFile:
+		// 文件导出器只用于trace
+		return nil, nil
+	case ExporterHTTP, ExporterAuto:
+		if config.Endpoint == "" {
+			config.Endpoint = DefaultHTTPEndpoint
+		}
+		exporter, err = createHTTPLogExporter(ctx, config)
+	case ExporterGRPC:
+		if config.Endpoint == "" {
+			config.Endpoint = DefaultGRPCEndpoint
+		}
+		exporter, err = createGRPCLogExporter(ctx, config)
+	case ExporterConsole, "":
+		exporter, err = stdoutlog.New(stdoutlog.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unsupported logs exporter type: %s", exporterType)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+		sdklog.WithResource(res),
+	), nil
+}
+
+// createHTTPLogExporter 创建HTTP日志导出器
+func createHTTPLogExporter(ctx context.Context, config Config) (sdklog.Exporter, error) {
+	config = config.forSignal(config.Logs)
+	endpoint, err := config.signalEndpoint(config.LogsEndpoint, "/v1/logs")
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []otlploghttp.Option{
+		otlploghttp.WithEndpoint(endpoint.host),
+		otlploghttp.WithURLPath(endpoint.path),
+	}
+	if endpoint.secure {
+		tlsConfig, err := config.TLS.build()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, otlploghttp.WithTLSClientConfig(tlsConfig))
+	} else {
+		opts = append(opts, otlploghttp.WithInsecure())
+	}
+	if len(config.Headers) > 0 {
+		opts = append(opts, otlploghttp.WithHeaders(config.Headers))
+	}
+	if config.Timeout > 0 {
+		opts = append(opts, otlploghttp.WithTimeout(config.Timeout))
+	}
+	if config.Compression == "gzip" {
+		opts = append(opts, otlploghttp.WithCompression(otlploghttp.GzipCompression))
+	}
+
+	return otlploghttp.New(ctx, opts...)
+}
+
+// createGRPCLogExporter 创建gRPC日志导出器
+func createGRPCLogExporter(ctx context.Context, config Config) (sdklog.Exporter, error) {
+	config = config.forSignal(config.Logs)
+	endpoint, err := config.signalEndpoint(config.LogsEndpoint, "")
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []otlploggrpc.Option{
+		otlploggrpc.WithEndpoint(endpoint.host),
+	}
+	if config.grpcSecure(endpoint) {
+		tlsConfig, err := config.TLS.build()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, otlploggrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		opts = append(opts, otlploggrpc.WithInsecure())
+	}
+	if len(config.Headers) > 0 {
+		opts = append(opts, otlploggrpc.WithHeaders(config.Headers))
+	}
+	if config.Timeout > 0 {
+		opts = append(opts, otlploggrpc.WithTimeout(config.Timeout))
+	}
+	if config.Compression == "gzip" {
+		opts = append(opts, otlploggrpc.WithCompressor("gzip"))
+	}
+
+	return otlploggrpc.New(ctx, opts...)
+}
+
+// GetLogger 获取logger实例
+func GetLogger(name string) otellog.Logger {
+	return global.GetLoggerProvider().Logger(name)
+}
+
+// EventsEnabled 返回是否以日志事件记录GenAI消息内容
+func EventsEnabled() bool {
+	return emitEvents.Load()
+}
+
+// RecordMessages 记录GenAI操作的输入输出消息
+//
+// 消息先按内容采集策略过滤和脱敏。启用GenAI事件时，消息作为
+// gen_ai.client.inference.operation.details日志事件发送，同时每条输入消息按角色发送
+// gen_ai.{system,user,assistant,tool}.message事件、每条输出消息发送gen_ai.choice事件，
+// 均通过ctx与当前span关联；否则直接写入span属性。details为事件附带的操作属性
+// （如gen_ai.operation.name、gen_ai.request.model），不会写入span。
+// 按策略没有可记录的消息时不发送事件。
+func RecordMessages(ctx context.Context, span trace2.Span, messages []attribute.KeyValue, details ...attribute.KeyValue) {
+	messages = applyContentPolicy(messages)
+
+	if !EventsEnabled() {
+		if len(messages) > 0 {
+			span.SetAttributes(messages...)
+		}
+		return
+	}
+	if len(messages) == 0 {
+		return
+	}
+
+	ctx = trace2.ContextWithSpan(ctx, span)
+	logger := GetLogger("gen-ai-example/telemetry")
+
+	var attrs []otellog.KeyValue
+	for _, kv := range details {
+		attrs = append(attrs, otellog.KeyValueFromAttribute(kv))
+	}
+	record := newEventRecord(InferenceDetailsEventName, attrs)
+	for _, kv := range messages {
+		record.AddAttributes(otellog.KeyValueFromAttribute(kv))
+	}
+	logger.Emit(ctx, record)
+
+	for _, kv := range messages {
+		for _, event := range messageEvents(kv) {
+			record := newEventRecord(event.name, attrs)
+			record.SetBody(logValue(event.body))
+			logger.Emit(ctx, record)
+		}
+	}
+}
+
+// newEventRecord 创建带有事件名称和属性的日志记录
+func newEventRecord(name string, attrs []otellog.KeyValue) otellog.Record {
+	var record otellog.Record
+	record.SetEventName(name)
+	record.SetTimestamp(time.Now())
+	record.SetSeverity(otellog.SeverityInfo)
+	record.AddAttributes(attrs...)
+	return record
+}
+
+// messageEvent 单条消息对应的事件
+type messageEvent struct {
+	name string
+	body map[string]interface{}
+}
+
+// messageEvents 将gen_ai.input.messages和gen_ai.output.messages拆分为每条消息的事件
+//
+// 输入消息按角色命名，未知角色的消息被跳过；输出消息的body包含index、finish_reason和message。
+// body为空（如只采集元数据时没有任何字段）的消息不生成事件。
+func messageEvents(kv attribute.KeyValue) []messageEvent {
+	if kv.Key != semconv.GenAIInputMessagesKey && kv.Key != semconv.GenAIOutputMessagesKey {
+		return nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(kv.Value.AsString()))
+	decoder.UseNumber()
+	var messages []map[string]interface{}
+	if err := decoder.Decode(&messages); err != nil {
+		return nil
+	}
+
+	var events []messageEvent
+	for i, message := range messages {
+		if len(message) == 0 {
+			continue
+		}
+		if kv.Key == semconv.GenAIInputMessagesKey {
+			role, _ := message["role"].(string)
+			if name, ok := messageEventNames[role]; ok {
+				events = append(events, messageEvent{name: name, body: message})
+			}
+			continue
+		}
+
+		body := map[string]interface{}{"index": i}
+		if finishReason, ok := message["finish_reason"]; ok {
+			body["finish_reason"] = finishReason
+			delete(message, "finish_reason")
+		}
+		if len(message) > 0 {
+			body["message"] = message
+		}
+		events = append(events, messageEvent{name: ChoiceEventName, body: body})
+	}
+	return events
+}
+
+// logValue 将JSON解码得到的值转换为日志的body值，map按键排序以保证输出稳定
+func logValue(value interface{}) otellog.Value {
+	switch v := value.(type) {
+	case string:
+		return otellog.StringValue(v)
+	case bool:
+		return otellog.BoolValue(v)
+	case int:
+		return otellog.IntValue(v)
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return otellog.Int64Value(n)
+		}
+		f, _ := v.Float64()
+		return otellog.Float64Value(f)
+	case []interface{}:
+		values := make([]otellog.Value, 0, len(v))
+		for _, item := range v {
+			values = append(values, logValue(item))
+		}
+		return otellog.SliceValue(values...)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		kvs := make([]otellog.KeyValue, 0, len(keys))
+		for _, key := range keys {
+			kvs = append(kvs, otellog.KeyValue{Key: key, Value: logValue(v[key])})
+		}
+		return otellog.MapValue(kvs...)
+	default:
+		return otellog.Value{}
+	}
+}
@@ -0,0 +1,187 @@
+package telemetry
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestLogsExporterType(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   ExporterType
+	}{
+		{name: "console without events", config: Config{ExporterType: ExporterConsole}, want: ExporterNone},
+		{name: "console with events", config: Config{ExporterType: ExporterConsole, EmitEvents: true}, want: ExporterConsole},
+		{name: "http with events", config: Config{ExporterType: ExporterHTTP, EmitEvents: true}, want: ExporterNone},
+		{name: "explicit otlp", config: Config{ExporterType: ExporterHTTP, LogsExporterType: ExporterHTTP, EmitEvents: true}, want: ExporterHTTP},
+		{name: "explicit none", config: Config{ExporterType: ExporterConsole, LogsExporterType: ExporterNone, EmitEvents: true}, want: ExporterNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.logsExporterType(); got != tt.want {
+				t.Errorf("logsExporterType() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewLoggerProviderDisabledForTracesOnlyBackend(t *testing.T) {
+	lp, err := newLoggerProvider(context.Background(), Config{
+		ExporterType: ExporterHTTP,
+		Endpoint:     "http://127.0.0.1:1",
+		EmitEvents:   true,
+	}, resource.Empty())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lp != nil {
+		t.Error("expected no logger provider without OTEL_LOGS_EXPORTER")
+	}
+}
+
+// recordingLogExporter 记录导出的日志，用于检查GenAI事件
+type recordingLogExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *recordingLogExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, record := range records {
+		e.records = append(e.records, record.Clone())
+	}
+	return nil
+}
+
+func (e *recordingLogExporter) Shutdown(context.Context) error { return nil }
+
+func (e *recordingLogExporter) ForceFlush(context.Context) error { return nil }
+
+// useEventRecorder 启用GenAI事件并安装记录日志的logger provider
+func useEventRecorder(t *testing.T) *recordingLogExporter {
+	t.Helper()
+
+	exporter := &recordingLogExporter{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)))
+	previous := global.GetLoggerProvider()
+	global.SetLoggerProvider(provider)
+	previousEvents := emitEvents.Swap(true)
+	t.Cleanup(func() {
+		global.SetLoggerProvider(previous)
+		emitEvents.Store(previousEvents)
+		_ = provider.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+// loggedEvent 便于比较的事件内容
+type loggedEvent struct {
+	name string
+	body string
+}
+
+func TestRecordMessagesEvents(t *testing.T) {
+	messages := []attribute.KeyValue{
+		semconv.GenAIInputMessagesKey.String(`[{"role":"system","content":"你是助手"},{"role":"user","content":"邮箱是alice@example.com"},{"role":"assistant","content":"","tool_calls":[{"id":"call_1","name":"search"}]},{"role":"tool","content":"结果","tool_call_id":"call_1"},{"role":"unknown","content":"x"}]`),
+		semconv.GenAIOutputMessagesKey.String(`[{"role":"assistant","content":"你好","finish_reason":"stop"}]`),
+	}
+
+	tests := []struct {
+		name    string
+		capture ContentCapture
+		want    []loggedEvent
+	}{
+		{name: "content capture off emits nothing", capture: ContentCaptureOff},
+		{
+			name:    "metadata",
+			capture: ContentCaptureMetadata,
+			want: []loggedEvent{
+				{name: InferenceDetailsEventName},
+				{name: "gen_ai.system.message", body: "{role:system}"},
+				{name: "gen_ai.user.message", body: "{role:user}"},
+				{name: "gen_ai.assistant.message", body: "{role:assistant}"},
+				{name: "gen_ai.tool.message", body: "{role:tool}"},
+				{name: ChoiceEventName, body: "{finish_reason:stop index:0 message:{role:assistant}}"},
+			},
+		},
+		{
+			name:    "full",
+			capture: ContentCaptureFull,
+			want: []loggedEvent{
+				{name: InferenceDetailsEventName},
+				{name: "gen_ai.system.message", body: "{content:你是助手 role:system}"},
+				{name: "gen_ai.user.message", body: "{content:邮箱是[REDACTED_EMAIL] role:user}"},
+				{name: "gen_ai.assistant.message", body: "{content: role:assistant tool_calls:[{id:call_1 name:search}]}"},
+				{name: "gen_ai.tool.message", body: "{content:结果 role:tool tool_call_id:call_1}"},
+				{name: ChoiceEventName, body: "{finish_reason:stop index:0 message:{content:你好 role:assistant}}"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useContentPolicy(t, tt.capture, nil)
+			exporter := useEventRecorder(t)
+
+			_, span := tracenoop.NewTracerProvider().Tracer("test").Start(context.Background(), "chat")
+			RecordMessages(context.Background(), span, messages, semconv.GenAIOperationNameChat, semconv.GenAIProviderNameOpenAI)
+
+			var got []loggedEvent
+			for _, record := range exporter.records {
+				event := loggedEvent{name: record.EventName()}
+				if record.Body().Kind() != otellog.KindEmpty {
+					event.body = formatLogValue(record.Body())
+				}
+				got = append(got, event)
+
+				var provider string
+				record.WalkAttributes(func(kv otellog.KeyValue) bool {
+					if kv.Key == string(semconv.GenAIProviderNameKey) {
+						provider = kv.Value.AsString()
+					}
+					return true
+				})
+				if provider != "openai" {
+					t.Errorf("%s event is missing gen_ai.provider.name", record.EventName())
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// formatLogValue 以紧凑的形式输出日志值，map按原顺序输出
+func formatLogValue(value otellog.Value) string {
+	switch value.Kind() {
+	case otellog.KindMap:
+		parts := make([]string, 0, len(value.AsMap()))
+		for _, kv := range value.AsMap() {
+			parts = append(parts, kv.Key+":"+formatLogValue(kv.Value))
+		}
+		return "{" + strings.Join(parts, " ") + "}"
+	case otellog.KindSlice:
+		parts := make([]string, 0, len(value.AsSlice()))
+		for _, item := range value.AsSlice() {
+			parts = append(parts, formatLogValue(item))
+		}
+		return "[" + strings.Join(parts, " ") + "]"
+	default:
+		return value.String()
+	}
+}
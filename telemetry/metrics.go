@@ -0,0 +1,299 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/semconv/v1.37.0/genaiconv"
+	"google.golang.org/grpc/credentials"
+)
+
+var (
+	// tokenUsageBuckets gen_ai.client.token.usage推荐的桶边界
+	tokenUsageBuckets = []float64{
+		1, 4, 16, 64, 256, 1024, 4096, 16384, 65536, 262144, 1048576, 4194304, 16777216, 67108864,
+	}
+	// operationDurationBuckets gen_ai.client.operation.duration推荐的桶边界（秒）
+	operationDurationBuckets = []float64{
+		0.01, 0.02, 0.04, 0.08, 0.16, 0.32, 0.64, 1.28, 2.56, 5.12, 10.24, 20.48, 40.96, 81.92,
+	}
+	// timeToFirstTokenBuckets gen_ai.server.time_to_first_token推荐的桶边界（秒）
+	timeToFirstTokenBuckets = []float64{
+		0.001, 0.005, 0.01, 0.02, 0.04, 0.06, 0.08, 0.1, 0.25, 0.5, 0.75, 1.0, 2.5, 5.0, 7.5, 10.0,
+	}
+	// timePerOutputTokenBuckets gen_ai.server.time_per_output_token推荐的桶边界（秒）
+	timePerOutputTokenBuckets = []float64{
+		0.01, 0.025, 0.05, 0.075, 0.1, 0.15, 0.2, 0.3, 0.4, 0.5, 0.75, 1.0, 2.5,
+	}
+)
+
+// metricsExporterType 返回metric导出器类型
+//
+// 未设置MetricsExporterType时只在trace使用console导出器时输出到console，否则不导出，
+// 以免向只接收trace的后端（如Tempo）推送metric。
+func (c Config) metricsExporterType() ExporterType {
+	if c.MetricsExporterType != "" {
+		return c.MetricsExporterType
+	}
+	if c.ExporterType == ExporterConsole || c.ExporterType == "" {
+		return ExporterConsole
+	}
+	return ExporterNone
+}
+
+// newMeterProvider 根据配置创建meter provider，metric导出被禁用时返回nil
+//
+// 配置了PrometheusAddr时还会返回提供/metrics的HTTP服务，调用方负责关闭。
+func newMeterProvider(ctx context.Context, config Config, res *resource.Resource) (*sdkmetric.MeterProvider, *http.Server, error) {
+	exporterType := config.metricsExporterType()
+
+	var exporter sdkmetric.Exporter
+	var err error
+
+	switch exporterType {
+	case ExporterNone, ExporterFile:
+		// 文件导出器只用于trace
+	case ExporterPrometheus:
+		if config.PrometheusAddr == "" {
+			config.PrometheusAddr = DefaultPrometheusAddr
+		}
+	case ExporterHTTP, ExporterAuto:
+		if config.Endpoint == "" {
+			config.Endpoint = DefaultHTTPEndpoint
+		}
+		exporter, err = createHTTPMetricExporter(ctx, config)
+	case ExporterGRPC:
+		if config.Endpoint == "" {
+			config.Endpoint = DefaultGRPCEndpoint
+		}
+		exporter, err = createGRPCMetricExporter(ctx, config)
+	case ExporterConsole, "":
+		exporter, err = stdoutmetric.New(stdoutmetric.WithPrettyPrint())
+	default:
+		return nil, nil, fmt.Errorf("unsupported metrics exporter type: %s", exporterType)
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var opts []sdkmetric.Option
+	if exporter != nil {
+		opts = append(opts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)))
+	}
+
+	var server *http.Server
+	if config.PrometheusAddr != "" {
+		var reader sdkmetric.Reader
+		reader, server, err = newPrometheusReader(config.PrometheusAddr)
+		if err != nil {
+			if exporter != nil {
+				err = errors.Join(err, exporter.Shutdown(ctx))
+			}
+			return nil, nil, err
+		}
+		opts = append(opts, sdkmetric.WithReader(reader))
+	}
+
+	if len(opts) == 0 {
+		return nil, nil, nil
+	}
+
+	opts = append(opts, sdkmetric.WithResource(res))
+	return sdkmetric.NewMeterProvider(opts...), server, nil
+}
+
+// createHTTPMetricExporter 创建HTTP metric导出器
+func createHTTPMetricExporter(ctx context.Context, config Config) (sdkmetric.Exporter, error) {
+	config = config.forSignal(config.Metrics)
+	endpoint, err := config.signalEndpoint(config.MetricsEndpoint, "/v1/metrics")
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []otlpmetrichttp.Option{
+		otlpmetrichttp.WithEndpoint(endpoint.host),
+		otlpmetrichttp.WithURLPath(endpoint.path),
+	}
+	if endpoint.secure {
+		tlsConfig, err := config.TLS.build()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, otlpmetrichttp.WithTLSClientConfig(tlsConfig))
+	} else {
+		opts = append(opts, otlpmetrichttp.WithInsecure())
+	}
+	if len(config.Headers) > 0 {
+		opts = append(opts, otlpmetrichttp.WithHeaders(config.Headers))
+	}
+	if config.Timeout > 0 {
+		opts = append(opts, otlpmetrichttp.WithTimeout(config.Timeout))
+	}
+	if config.Compression == "gzip" {
+		opts = append(opts, otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression))
+	}
+
+	return otlpmetrichttp.New(ctx, opts...)
+}
+
+// createGRPCMetricExporter 创建gRPC metric导出器
+func createGRPCMetricExporter(ctx context.Context, config Config) (sdkmetric.Exporter, error) {
+	config = config.forSignal(config.Metrics)
+	endpoint, err := config.signalEndpoint(config.MetricsEndpoint, "")
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []otlpmetricgrpc.Option{
+		otlpmetricgrpc.WithEndpoint(endpoint.host),
+	}
+	if config.grpcSecure(endpoint) {
+		tlsConfig, err := config.TLS.build()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	}
+	if len(config.Headers) > 0 {
+		opts = append(opts, otlpmetricgrpc.WithHeaders(config.Headers))
+	}
+	if config.Timeout > 0 {
+		opts = append(opts, otlpmetricgrpc.WithTimeout(config.Timeout))
+	}
+	if config.Compression == "gzip" {
+		opts = append(opts, otlpmetricgrpc.WithCompressor("gzip"))
+	}
+
+	return otlpmetricgrpc.New(ctx, opts...)
+}
+
+// GetMeter 获取meter实例
+func GetMeter(name string) metric.Meter {
+	return otel.Meter(name)
+}
+
+// GenAIMetricAttrs GenAI客户端指标的公共属性
+type GenAIMetricAttrs struct {
+	OperationName string
+	ProviderName  string
+	RequestModel  string
+	ResponseModel string
+	// ServerAddress 和 ServerPort 为空时不记录
+	ServerAddress string
+	ServerPort    int
+}
+
+// attributes 转换为指标属性
+func (a GenAIMetricAttrs) attributes() []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.GenAIOperationNameKey.String(a.OperationName),
+		semconv.GenAIProviderNameKey.String(a.ProviderName),
+	}
+	if a.RequestModel != "" {
+		attrs = append(attrs, semconv.GenAIRequestModel(a.RequestModel))
+	}
+	if a.ResponseModel != "" {
+		attrs = append(attrs, semconv.GenAIResponseModel(a.ResponseModel))
+	}
+	if a.ServerAddress != "" {
+		attrs = append(attrs, semconv.ServerAddress(a.ServerAddress))
+	}
+	if a.ServerPort > 0 {
+		attrs = append(attrs, semconv.ServerPort(a.ServerPort))
+	}
+	return attrs
+}
+
+// GenAIMetrics 记录GenAI语义约定指标
+// (gen_ai.client.token.usage 和 gen_ai.client.operation.duration，
+// 以及流式响应的 gen_ai.server.time_to_first_token 和 gen_ai.server.time_per_output_token)
+type GenAIMetrics struct {
+	tokenUsage         genaiconv.ClientTokenUsage
+	operationDuration  genaiconv.ClientOperationDuration
+	timeToFirstToken   genaiconv.ServerTimeToFirstToken
+	timePerOutputToken genaiconv.ServerTimePerOutputToken
+}
+
+// NewGenAIMetrics 使用meter创建GenAI客户端指标
+func NewGenAIMetrics(meter metric.Meter) *GenAIMetrics {
+	tokenUsage, err := genaiconv.NewClientTokenUsage(meter,
+		metric.WithExplicitBucketBoundaries(tokenUsageBuckets...),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	operationDuration, err := genaiconv.NewClientOperationDuration(meter,
+		metric.WithExplicitBucketBoundaries(operationDurationBuckets...),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	timeToFirstToken, err := genaiconv.NewServerTimeToFirstToken(meter,
+		metric.WithExplicitBucketBoundaries(timeToFirstTokenBuckets...),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	timePerOutputToken, err := genaiconv.NewServerTimePerOutputToken(meter,
+		metric.WithExplicitBucketBoundaries(timePerOutputTokenBuckets...),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return &GenAIMetrics{
+		tokenUsage:         tokenUsage,
+		operationDuration:  operationDuration,
+		timeToFirstToken:   timeToFirstToken,
+		timePerOutputToken: timePerOutputToken,
+	}
+}
+
+// RecordTokenUsage 记录输入和输出token数量
+func (m *GenAIMetrics) RecordTokenUsage(ctx context.Context, attrs GenAIMetricAttrs, inputTokens, outputTokens int) {
+	base := attrs.attributes()
+	base = base[:len(base):len(base)]
+	m.tokenUsage.Inst().Record(ctx, int64(inputTokens), metric.WithAttributes(
+		append(base, semconv.GenAITokenTypeInput)...,
+	))
+	m.tokenUsage.Inst().Record(ctx, int64(outputTokens), metric.WithAttributes(
+		append(base, semconv.GenAITokenTypeOutput)...,
+	))
+}
+
+// RecordDuration 记录操作耗时，err非空时附加error.type属性
+func (m *GenAIMetrics) RecordDuration(ctx context.Context, attrs GenAIMetricAttrs, duration time.Duration, err error) {
+	kvs := attrs.attributes()
+	if err != nil {
+		kvs = append(kvs, semconv.ErrorTypeOther)
+	}
+	m.operationDuration.Inst().Record(ctx, duration.Seconds(), metric.WithAttributes(kvs...))
+}
+
+// RecordTimeToFirstToken 记录流式响应中收到第一个分片的耗时
+func (m *GenAIMetrics) RecordTimeToFirstToken(ctx context.Context, attrs GenAIMetricAttrs, duration time.Duration) {
+	m.timeToFirstToken.Inst().Record(ctx, duration.Seconds(), metric.WithAttributes(attrs.attributes()...))
+}
+
+// RecordTimePerOutputToken 记录第一个分片之后平均每个输出token的耗时
+func (m *GenAIMetrics) RecordTimePerOutputToken(ctx context.Context, attrs GenAIMetricAttrs, duration time.Duration) {
+	m.timePerOutputToken.Inst().Record(ctx, duration.Seconds(), metric.WithAttributes(attrs.attributes()...))
+}
@@ -0,0 +1,51 @@
+package telemetry
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+func TestMetricsExporterType(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   ExporterType
+	}{
+		{name: "console traces", config: Config{ExporterType: ExporterConsole}, want: ExporterConsole},
+		{name: "default traces", config: Config{}, want: ExporterConsole},
+		{name: "http traces", config: Config{ExporterType: ExporterHTTP}, want: ExporterNone},
+		{name: "grpc traces", config: Config{ExporterType: ExporterGRPC}, want: ExporterNone},
+		{name: "auto traces", config: Config{ExporterType: ExporterAuto}, want: ExporterNone},
+		{name: "explicit otlp", config: Config{ExporterType: ExporterHTTP, MetricsExporterType: ExporterHTTP}, want: ExporterHTTP},
+		{name: "explicit none", config: Config{ExporterType: ExporterConsole, MetricsExporterType: ExporterNone}, want: ExporterNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.metricsExporterType(); got != tt.want {
+				t.Errorf("metricsExporterType() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMeterProviderPrometheusListenError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	_, _, err = newMeterProvider(context.Background(), Config{
+		ExporterType:        ExporterHTTP,
+		MetricsExporterType: ExporterHTTP,
+		Endpoint:            "http://127.0.0.1:1",
+		PrometheusAddr:      listener.Addr().String(),
+	}, resource.Empty())
+	if err == nil {
+		t.Fatal("expected an error when the Prometheus address is in use")
+	}
+}
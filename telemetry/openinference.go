@@ -0,0 +1,250 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// openInferenceSpanKinds gen_ai.operation.name对应的openinference.span.kind
+var openInferenceSpanKinds = map[string]string{
+	"chat":             "LLM",
+	"text_completion":  "LLM",
+	"generate_content": "LLM",
+	"embeddings":       "EMBEDDING",
+	"execute_tool":     "TOOL",
+	"create_agent":     "AGENT",
+	"invoke_agent":     "AGENT",
+}
+
+// openInferenceKeys 直接改名即可映射的属性
+var openInferenceKeys = map[attribute.Key]attribute.Key{
+	semconv.GenAIProviderNameKey:    "llm.provider",
+	semconv.GenAIConversationIDKey:  "session.id",
+	semconv.GenAIAgentNameKey:       "agent.name",
+	semconv.GenAIToolNameKey:        "tool.name",
+	semconv.GenAIToolDescriptionKey: "tool.description",
+	semconv.GenAIToolCallIDKey:      "tool_call.id",
+}
+
+// openInferenceExporter 在导出前为span追加OpenInference属性，保留原有的gen_ai.*属性
+type openInferenceExporter struct {
+	next trace.SpanExporter
+}
+
+// newOpenInferenceExporter 创建追加OpenInference属性的导出器
+func newOpenInferenceExporter(next trace.SpanExporter) trace.SpanExporter {
+	return &openInferenceExporter{next: next}
+}
+
+// ExportSpans 实现trace.SpanExporter接口
+func (e *openInferenceExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	translated := make([]trace.ReadOnlySpan, len(spans))
+	for i, span := range spans {
+		attrs := span.Attributes()
+		translated[i] = translatedSpan{
+			ReadOnlySpan: span,
+			attrs:        append(attrs[:len(attrs):len(attrs)], openInferenceAttributes(attrs)...),
+		}
+	}
+	return e.next.ExportSpans(ctx, translated)
+}
+
+// Shutdown 实现trace.SpanExporter接口
+func (e *openInferenceExporter) Shutdown(ctx context.Context) error {
+	return e.next.Shutdown(ctx)
+}
+
+// openInferenceAttributes 根据gen_ai.*属性生成OpenInference属性
+func openInferenceAttributes(attrs []attribute.KeyValue) []attribute.KeyValue {
+	values := make(map[attribute.Key]attribute.Value, len(attrs))
+	for _, kv := range attrs {
+		values[kv.Key] = kv.Value
+	}
+
+	kind := "CHAIN"
+	if operation, ok := values[semconv.GenAIOperationNameKey]; ok {
+		if k, ok := openInferenceSpanKinds[operation.AsString()]; ok {
+			kind = k
+		}
+	}
+	result := []attribute.KeyValue{attribute.String("openinference.span.kind", kind)}
+
+	for _, kv := range attrs {
+		if key, ok := openInferenceKeys[kv.Key]; ok {
+			result = append(result, attribute.KeyValue{Key: key, Value: kv.Value})
+		}
+	}
+
+	if model, ok := values[semconv.GenAIResponseModelKey]; ok {
+		result = append(result, attribute.String("llm.model_name", model.AsString()))
+	} else if model, ok := values[semconv.GenAIRequestModelKey]; ok {
+		result = append(result, attribute.String("llm.model_name", model.AsString()))
+	}
+	if provider, ok := values[semconv.GenAIProviderNameKey]; ok {
+		result = append(result, attribute.String("llm.system", provider.AsString()))
+	}
+
+	input, hasInput := values[semconv.GenAIUsageInputTokensKey]
+	output, hasOutput := values[semconv.GenAIUsageOutputTokensKey]
+	if hasInput {
+		result = append(result, attribute.Int64("llm.token_count.prompt", input.AsInt64()))
+	}
+	if hasOutput {
+		result = append(result, attribute.Int64("llm.token_count.completion", output.AsInt64()))
+	}
+	if hasInput || hasOutput {
+		result = append(result, attribute.Int64("llm.token_count.total", input.AsInt64()+output.AsInt64()))
+	}
+
+	if cacheRead, ok := values["gen_ai.usage.cache_read.input_tokens"]; ok {
+		result = append(result, attribute.Int64("llm.token_count.prompt_details.cache_read", cacheRead.AsInt64()))
+	}
+	if cacheWrite, ok := values["gen_ai.usage.cache_creation.input_tokens"]; ok {
+		result = append(result, attribute.Int64("llm.token_count.prompt_details.cache_write", cacheWrite.AsInt64()))
+	}
+
+	if params := invocationParameters(attrs); params != "" {
+		result = append(result, attribute.String("llm.invocation_parameters", params))
+	}
+
+	if messages, ok := values[semconv.GenAIInputMessagesKey]; ok {
+		result = append(result,
+			attribute.String("input.value", messages.AsString()),
+			attribute.String("input.mime_type", "application/json"),
+		)
+		result = append(result, flattenMessages("llm.input_messages", messages.AsString())...)
+	}
+	if messages, ok := values[semconv.GenAIOutputMessagesKey]; ok {
+		result = append(result,
+			attribute.String("output.value", messages.AsString()),
+			attribute.String("output.mime_type", "application/json"),
+		)
+		result = append(result, flattenMessages("llm.output_messages", messages.AsString())...)
+	}
+
+	// 工具span的参数和结果作为输入输出
+	if arguments, ok := values["gen_ai.tool.call.arguments"]; ok {
+		result = append(result,
+			attribute.String("tool.parameters", arguments.AsString()),
+			attribute.String("input.value", arguments.AsString()),
+			attribute.String("input.mime_type", "application/json"),
+		)
+	}
+	if toolResult, ok := values["gen_ai.tool.call.result"]; ok {
+		result = append(result,
+			attribute.String("output.value", toolResult.AsString()),
+			attribute.String("output.mime_type", "application/json"),
+		)
+	}
+
+	return result
+}
+
+// invocationParameters 将gen_ai.request.*中的请求参数编码为JSON
+func invocationParameters(attrs []attribute.KeyValue) string {
+	params := make(map[string]interface{})
+	for _, kv := range attrs {
+		name, ok := strings.CutPrefix(string(kv.Key), "gen_ai.request.")
+		if !ok || kv.Key == semconv.GenAIRequestModelKey {
+			continue
+		}
+		params[name] = kv.Value.AsInterface()
+	}
+	if len(params) == 0 {
+		return ""
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// genAIMessage gen_ai.input.messages/gen_ai.output.messages中的一条消息
+//
+// 同时兼容content字符串加tool_calls和语义约定中的parts数组两种格式，
+// content也可以是数组或对象等结构化内容。
+type genAIMessage struct {
+	Role         string          `json:"role"`
+	Content      json.RawMessage `json:"content"`
+	ToolCalls    []genAIMessage  `json:"tool_calls"`
+	ToolCallID   string          `json:"tool_call_id"`
+	Parts        []genAIMessage  `json:"parts"`
+	Type         string          `json:"type"`
+	ID           string          `json:"id"`
+	Name         string          `json:"name"`
+	Arguments    interface{}     `json:"arguments"`
+	FinishReason string          `json:"finish_reason"`
+}
+
+// messageContent 返回content的文本，字符串直接返回，结构化内容返回原始JSON文本
+func messageContent(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	return string(raw)
+}
+
+// flattenMessages 将消息JSON展开为prefix.{i}.message.*格式的属性，无法解析时返回nil
+func flattenMessages(prefix, raw string) []attribute.KeyValue {
+	var messages []genAIMessage
+	if err := json.Unmarshal([]byte(raw), &messages); err != nil {
+		return nil
+	}
+
+	var result []attribute.KeyValue
+	for i, message := range messages {
+		base := fmt.Sprintf("%s.%d.message", prefix, i)
+		if message.Role != "" {
+			result = append(result, attribute.String(base+".role", message.Role))
+		}
+
+		var contents []string
+		if content := messageContent(message.Content); content != "" {
+			contents = append(contents, content)
+		}
+		toolCalls := message.ToolCalls
+		toolCallID := message.ToolCallID
+		for _, part := range message.Parts {
+			switch part.Type {
+			case "text", "":
+				if content := messageContent(part.Content); content != "" {
+					contents = append(contents, content)
+				}
+			case "tool_call":
+				toolCalls = append(toolCalls, part)
+			case "tool_call_response":
+				toolCallID = part.ID
+			}
+		}
+		for j, call := range toolCalls {
+			callBase := fmt.Sprintf("%s.tool_calls.%d.tool_call", base, j)
+			if call.ID != "" {
+				result = append(result, attribute.String(callBase+".id", call.ID))
+			}
+			result = append(result, attribute.String(callBase+".function.name", call.Name))
+			if call.Arguments != nil {
+				arguments, _ := json.Marshal(call.Arguments)
+				result = append(result, attribute.String(callBase+".function.arguments", string(arguments)))
+			}
+		}
+		if toolCallID != "" {
+			result = append(result, attribute.String(base+".tool_call_id", toolCallID))
+		}
+		if len(contents) > 0 {
+			result = append(result, attribute.String(base+".content", strings.Join(contents, "\n")))
+		}
+	}
+	return result
+}
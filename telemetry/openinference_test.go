@@ -0,0 +1,64 @@
+package telemetry
+
+import (
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestFlattenMessages(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []attribute.KeyValue
+	}{
+		{
+			name: "string content",
+			raw:  `[{"role":"user","content":"你好"}]`,
+			want: []attribute.KeyValue{
+				attribute.String("llm.input_messages.0.message.role", "user"),
+				attribute.String("llm.input_messages.0.message.content", "你好"),
+			},
+		},
+		{
+			name: "structured content falls back to raw JSON",
+			raw:  `[{"role":"user","content":[{"type":"text","text":"看图"},{"type":"image_url","image_url":{"url":"https://example.com/a.png"}}]}]`,
+			want: []attribute.KeyValue{
+				attribute.String("llm.input_messages.0.message.role", "user"),
+				attribute.String("llm.input_messages.0.message.content",
+					`[{"type":"text","text":"看图"},{"type":"image_url","image_url":{"url":"https://example.com/a.png"}}]`),
+			},
+		},
+		{
+			name: "null content with tool calls",
+			raw:  `[{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","name":"get_weather","arguments":{"city":"北京"}}]}]`,
+			want: []attribute.KeyValue{
+				attribute.String("llm.input_messages.0.message.role", "assistant"),
+				attribute.String("llm.input_messages.0.message.tool_calls.0.tool_call.id", "call_1"),
+				attribute.String("llm.input_messages.0.message.tool_calls.0.tool_call.function.name", "get_weather"),
+				attribute.String("llm.input_messages.0.message.tool_calls.0.tool_call.function.arguments", `{"city":"北京"}`),
+			},
+		},
+		{
+			name: "parts",
+			raw:  `[{"role":"user","parts":[{"type":"text","content":"第一段"},{"type":"text","content":"第二段"}]}]`,
+			want: []attribute.KeyValue{
+				attribute.String("llm.input_messages.0.message.role", "user"),
+				attribute.String("llm.input_messages.0.message.content", "第一段\n第二段"),
+			},
+		},
+		{
+			name: "invalid JSON",
+			raw:  `[{"role":"user"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := flattenMessages("llm.input_messages", tt.raw); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("flattenMessages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
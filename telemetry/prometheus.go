@@ -0,0 +1,44 @@
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// DefaultPrometheusAddr Prometheus抓取端点的默认监听地址
+const DefaultPrometheusAddr = "localhost:9464"
+
+// newPrometheusReader 创建Prometheus reader，并在addr的/metrics上提供抓取端点
+func newPrometheusReader(addr string) (sdkmetric.Reader, *http.Server, error) {
+	registry := prometheus.NewRegistry()
+	reader, err := otelprom.New(otelprom.WithRegisterer(registry))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create prometheus exporter: %v", err)
+	}
+
+	// 先监听端口，使地址冲突等错误在初始化阶段暴露
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to listen on %s: %v", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	server := &http.Server{Handler: mux}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Prometheus metrics server stopped: %v", err)
+		}
+	}()
+
+	return reader, server, nil
+}
@@ -0,0 +1,75 @@
+package telemetry
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// defaultPropagators 未配置OTEL_PROPAGATORS时使用的propagator
+var defaultPropagators = []string{"tracecontext", "baggage"}
+
+// newPropagator 根据OTEL_PROPAGATORS规范的名称创建组合propagator
+//
+// 支持tracecontext、baggage、b3（单头部）、b3multi（多头部）和none，names为空时使用tracecontext,baggage。
+// 与规范一致，不支持的名称记录警告后忽略。
+func newPropagator(names []string) propagation.TextMapPropagator {
+	if len(names) == 0 {
+		names = defaultPropagators
+	}
+
+	var propagators []propagation.TextMapPropagator
+	for _, name := range names {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "tracecontext":
+			propagators = append(propagators, propagation.TraceContext{})
+		case "baggage":
+			propagators = append(propagators, propagation.Baggage{})
+		case "b3":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case "b3multi":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case "none", "":
+		default:
+			log.Printf("Ignoring unsupported propagator: %q", name)
+		}
+	}
+
+	return propagation.NewCompositeTextMapPropagator(propagators...)
+}
+
+// parsePropagators 解析OTEL_PROPAGATORS
+func parsePropagators(raw string) []string {
+	var names []string
+	for _, name := range strings.Split(raw, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// InjectHTTP 将ctx中的trace上下文和baggage写入HTTP请求头
+func InjectHTTP(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// ExtractHTTP 从HTTP请求头中恢复trace上下文和baggage
+func ExtractHTTP(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// InjectMap 将ctx中的trace上下文和baggage写入map，适用于消息队列、进程参数等非HTTP载体
+func InjectMap(ctx context.Context, carrier map[string]string) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(carrier))
+}
+
+// ExtractMap 从map中恢复trace上下文和baggage
+func ExtractMap(ctx context.Context, carrier map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
@@ -0,0 +1,147 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	trace2 "go.opentelemetry.io/otel/trace"
+)
+
+// testSpanContext 用于传播测试的已采样SpanContext
+var testSpanContext = trace2.NewSpanContext(trace2.SpanContextConfig{
+	TraceID:    trace2.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+	SpanID:     trace2.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	TraceFlags: trace2.FlagsSampled,
+})
+
+// testContext 返回带有testSpanContext和baggage的context
+func testContext(t *testing.T) context.Context {
+	t.Helper()
+
+	member, err := baggage.NewMember("tenant", "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bag, err := baggage.New(member)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := trace2.ContextWithSpanContext(context.Background(), testSpanContext)
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+func TestParsePropagators(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want []string
+	}{
+		{raw: "", want: nil},
+		{raw: "tracecontext", want: []string{"tracecontext"}},
+		{raw: " tracecontext , b3 ,", want: []string{"tracecontext", "b3"}},
+	}
+
+	for _, tt := range tests {
+		if got := parsePropagators(tt.raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parsePropagators(%q) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestNewPropagator(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		want    []string
+		warning string
+	}{
+		{name: "default", want: []string{"baggage", "traceparent"}},
+		{name: "tracecontext", env: "tracecontext", want: []string{"traceparent"}},
+		{name: "baggage", env: "baggage", want: []string{"baggage"}},
+		{name: "b3 single header", env: "b3", want: []string{"b3"}},
+		{name: "b3 multiple headers", env: "b3multi", want: []string{"x-b3-sampled", "x-b3-spanid", "x-b3-traceid"}},
+		{name: "combined", env: "tracecontext,baggage,b3", want: []string{"b3", "baggage", "traceparent"}},
+		{name: "none", env: "none", want: nil},
+		{name: "unknown names are ignored", env: "tracecontext,xray", want: []string{"traceparent"}, warning: `unsupported propagator: "xray"`},
+		{name: "only unknown names", env: "jaeger", want: nil, warning: `unsupported propagator: "jaeger"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_PROPAGATORS", tt.env)
+			logs := captureLog(t)
+
+			propagator := newPropagator(GetConfigFromEnv().Propagators)
+			carrier := propagation.MapCarrier{}
+			propagator.Inject(testContext(t), carrier)
+
+			got := carrier.Keys()
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("injected headers = %v, want %v", got, tt.want)
+			}
+			if tt.warning == "" && logs.Len() > 0 {
+				t.Errorf("unexpected warning: %s", logs)
+			}
+			if !strings.Contains(logs.String(), tt.warning) {
+				t.Errorf("warning = %q, want %q", logs, tt.warning)
+			}
+		})
+	}
+}
+
+func TestPropagationRoundTrip(t *testing.T) {
+	carriers := []struct {
+		name string
+		// roundTrip 写入载体后再从载体中恢复context
+		roundTrip func(ctx context.Context) context.Context
+	}{
+		{
+			name: "http",
+			roundTrip: func(ctx context.Context) context.Context {
+				header := http.Header{}
+				InjectHTTP(ctx, header)
+				return ExtractHTTP(context.Background(), header)
+			},
+		},
+		{
+			name: "map",
+			roundTrip: func(ctx context.Context) context.Context {
+				carrier := map[string]string{}
+				InjectMap(ctx, carrier)
+				return ExtractMap(context.Background(), carrier)
+			},
+		},
+	}
+	propagators := [][]string{{"tracecontext", "baggage"}, {"b3", "baggage"}, {"b3multi", "baggage"}}
+
+	previous := otel.GetTextMapPropagator()
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	for _, names := range propagators {
+		for _, carrier := range carriers {
+			t.Run(strings.Join(names, ",")+"/"+carrier.name, func(t *testing.T) {
+				otel.SetTextMapPropagator(newPropagator(names))
+
+				extracted := carrier.roundTrip(testContext(t))
+
+				got := trace2.SpanContextFromContext(extracted)
+				if !got.IsRemote() {
+					t.Error("extracted span context is not remote")
+				}
+				if !got.Equal(testSpanContext.WithRemote(true)) {
+					t.Errorf("span context = %v, want %v", got, testSpanContext)
+				}
+				if value := baggage.FromContext(extracted).Member("tenant").Value(); value != "acme" {
+					t.Errorf("baggage tenant = %q, want acme", value)
+				}
+			})
+		}
+	}
+}
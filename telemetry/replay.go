@@ -0,0 +1,206 @@
+package telemetry
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// maxReplayLineBytes 单行OTLP-JSON的最大长度
+const maxReplayLineBytes = 64 << 20
+
+// ReplayOptions 定义回放选项
+type ReplayOptions struct {
+	// RewriteTraceIDs 为每个trace生成新的trace ID，便于重复回放同一文件
+	RewriteTraceIDs bool
+	// ShiftToNow 平移所有时间戳，使文件中最晚结束的span在当前时间结束
+	ShiftToNow bool
+}
+
+// Replay 读取文件导出器写入的OTLP-JSON文件，并重新发送到config配置的OTLP端点
+//
+// ExporterGRPC使用OTLP/gRPC，其他类型均使用OTLP/HTTP。返回发送的span数量。
+func Replay(ctx context.Context, config Config, path string, opts ReplayOptions) (int, error) {
+	var client otlptrace.Client
+	var err error
+
+	switch config.ExporterType {
+	case ExporterGRPC:
+		if config.Endpoint == "" {
+			config.Endpoint = DefaultGRPCEndpoint
+		}
+		client, err = newGRPCTraceClient(config)
+	default:
+		if config.Endpoint == "" {
+			config.Endpoint = DefaultHTTPEndpoint
+		}
+		client, err = newHTTPTraceClient(config)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var offset time.Duration
+	if opts.ShiftToNow {
+		latest, err := latestEndTime(path)
+		if err != nil {
+			return 0, err
+		}
+		if latest > 0 {
+			offset = time.Duration(time.Now().UnixNano() - int64(latest))
+		}
+	}
+
+	if err := client.Start(ctx); err != nil {
+		return 0, fmt.Errorf("failed to start OTLP client: %v", err)
+	}
+	defer func() {
+		if err := client.Stop(context.Background()); err != nil {
+			log.Printf("Error stopping OTLP client: %v", err)
+		}
+	}()
+
+	traceIDs := make(map[string][]byte)
+	sent := 0
+
+	err = scanOTLPJSON(path, func(rs *tracepb.ResourceSpans) error {
+		if opts.RewriteTraceIDs {
+			if err := rewriteTraceIDs(rs, traceIDs); err != nil {
+				return err
+			}
+		}
+		if offset != 0 {
+			shiftTimestamps(rs, offset)
+		}
+
+		if err := client.UploadTraces(ctx, []*tracepb.ResourceSpans{rs}); err != nil {
+			return fmt.Errorf("failed to upload traces: %v", err)
+		}
+		sent += countSpans(rs)
+		return nil
+	})
+
+	return sent, err
+}
+
+// scanOTLPJSON 逐行解析OTLP-JSON文件，跳过空行
+func scanOTLPJSON(path string, fn func(rs *tracepb.ResourceSpans) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open replay file: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxReplayLineBytes)
+
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		rs, err := UnmarshalOTLPJSON(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", lineNo, err)
+		}
+		if err := fn(rs); err != nil {
+			return fmt.Errorf("line %d: %v", lineNo, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read replay file: %v", err)
+	}
+	return nil
+}
+
+// latestEndTime 返回文件中所有span最晚的结束时间（Unix纳秒）
+func latestEndTime(path string) (uint64, error) {
+	var latest uint64
+	err := scanOTLPJSON(path, func(rs *tracepb.ResourceSpans) error {
+		for _, ss := range rs.GetScopeSpans() {
+			for _, span := range ss.GetSpans() {
+				if span.GetEndTimeUnixNano() > latest {
+					latest = span.GetEndTimeUnixNano()
+				}
+			}
+		}
+		return nil
+	})
+	return latest, err
+}
+
+// rewriteTraceIDs 将trace ID替换为新的随机ID，同一个原始ID始终映射到同一个新ID
+func rewriteTraceIDs(rs *tracepb.ResourceSpans, mapping map[string][]byte) error {
+	replace := func(id []byte) ([]byte, error) {
+		if len(id) == 0 {
+			return id, nil
+		}
+		if newID, ok := mapping[string(id)]; ok {
+			return newID, nil
+		}
+		newID := make([]byte, 16)
+		if _, err := rand.Read(newID); err != nil {
+			return nil, fmt.Errorf("failed to generate trace ID: %v", err)
+		}
+		mapping[string(id)] = newID
+		return newID, nil
+	}
+
+	for _, ss := range rs.GetScopeSpans() {
+		for _, span := range ss.GetSpans() {
+			newID, err := replace(span.TraceId)
+			if err != nil {
+				return err
+			}
+			span.TraceId = newID
+
+			// 只改写已经回放过的trace的链接
+			for _, link := range span.GetLinks() {
+				if newID, ok := mapping[string(link.TraceId)]; ok {
+					link.TraceId = newID
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// shiftTimestamps 按offset平移span及其事件的时间戳
+func shiftTimestamps(rs *tracepb.ResourceSpans, offset time.Duration) {
+	shift := func(ts uint64) uint64 {
+		if ts == 0 {
+			return 0
+		}
+		return uint64(int64(ts) + int64(offset))
+	}
+
+	for _, ss := range rs.GetScopeSpans() {
+		for _, span := range ss.GetSpans() {
+			span.StartTimeUnixNano = shift(span.StartTimeUnixNano)
+			span.EndTimeUnixNano = shift(span.EndTimeUnixNano)
+			for _, event := range span.GetEvents() {
+				event.TimeUnixNano = shift(event.TimeUnixNano)
+			}
+		}
+	}
+}
+
+// countSpans 统计ResourceSpans中的span数量
+func countSpans(rs *tracepb.ResourceSpans) int {
+	count := 0
+	for _, ss := range rs.GetScopeSpans() {
+		count += len(ss.GetSpans())
+	}
+	return count
+}
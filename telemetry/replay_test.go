@@ -0,0 +1,105 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+	trace2 "go.opentelemetry.io/otel/trace"
+)
+
+// writeTestTrace 使用文件导出器写入一个包含父子span的trace，返回两个span的SpanContext
+func writeTestTrace(t *testing.T, path string) (parent, child trace2.SpanContext) {
+	t.Helper()
+
+	ctx := context.Background()
+	exporter, err := createFileExporter(ctx, Config{FilePath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := trace.NewTracerProvider(trace.WithSyncer(exporter))
+	tracer := provider.Tracer("test")
+
+	spanCtx, parentSpan := tracer.Start(ctx, "parent")
+	_, childSpan := tracer.Start(spanCtx, "child")
+	childSpan.End()
+	parentSpan.End()
+
+	if err := provider.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return parentSpan.SpanContext(), childSpan.SpanContext()
+}
+
+func TestReplayRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ReplayOptions
+	}{
+		{name: "original IDs"},
+		{name: "rewritten trace IDs", opts: ReplayOptions{RewriteTraceIDs: true, ShiftToNow: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "traces.jsonl")
+			parent, child := writeTestTrace(t, path)
+			server, received := newOTLPReceiver(t)
+
+			sent, err := Replay(context.Background(), Config{Endpoint: server.URL}, path, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sent != 2 {
+				t.Errorf("sent %d spans, want 2", sent)
+			}
+
+			spans := make(map[string]spanIDs)
+			for _, span := range received() {
+				spans[span.GetName()] = spanIDs{traceID: span.GetTraceId(), spanID: span.GetSpanId(), parentID: span.GetParentSpanId()}
+			}
+			if len(spans) != 2 {
+				t.Fatalf("received spans %v, want parent and child", spans)
+			}
+			gotParent, gotChild := spans["parent"], spans["child"]
+
+			wantTraceID := parent.TraceID()
+			if tt.opts.RewriteTraceIDs {
+				if bytes.Equal(gotParent.traceID, wantTraceID[:]) {
+					t.Error("trace ID was not rewritten")
+				}
+				copy(wantTraceID[:], gotParent.traceID)
+			}
+			parentID, childID := parent.SpanID(), child.SpanID()
+			want := map[string]spanIDs{
+				"parent": {traceID: wantTraceID[:], spanID: parentID[:]},
+				"child":  {traceID: wantTraceID[:], spanID: childID[:], parentID: parentID[:]},
+			}
+			for name, got := range map[string]spanIDs{"parent": gotParent, "child": gotChild} {
+				if !got.equal(want[name]) {
+					t.Errorf("%s IDs = %s, want %s", name, got, want[name])
+				}
+			}
+		})
+	}
+}
+
+// spanIDs 回放后收到的span的ID
+type spanIDs struct {
+	traceID  []byte
+	spanID   []byte
+	parentID []byte
+}
+
+// equal 判断两组ID是否相同
+func (s spanIDs) equal(other spanIDs) bool {
+	return bytes.Equal(s.traceID, other.traceID) && bytes.Equal(s.spanID, other.spanID) && bytes.Equal(s.parentID, other.parentID)
+}
+
+// String 以十六进制输出ID
+func (s spanIDs) String() string {
+	return fmt.Sprintf("trace=%x span=%x parent=%x", s.traceID, s.spanID, s.parentID)
+}
@@ -0,0 +1,99 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// DefaultServiceName 未配置服务名称时使用的service.name
+const DefaultServiceName = "gen-ai-example"
+
+// newResource 合并SDK、主机、进程、操作系统、容器检测器以及环境变量得到的resource
+//
+// 优先级从低到高依次为：默认值与检测器、OTEL_RESOURCE_ATTRIBUTES/OTEL_SERVICE_NAME、Config中显式设置的字段。
+// 部分检测器失败时记录日志并使用已检测到的属性。
+func newResource(ctx context.Context, config Config) (*resource.Resource, error) {
+	defaults := []attribute.KeyValue{
+		semconv.ServiceName(DefaultServiceName),
+	}
+	if version := buildVersion(); version != "" {
+		defaults = append(defaults, semconv.ServiceVersion(version))
+	}
+
+	var overrides []attribute.KeyValue
+	if config.ServiceName != "" {
+		overrides = append(overrides, semconv.ServiceName(config.ServiceName))
+	}
+	if config.ServiceVersion != "" {
+		overrides = append(overrides, semconv.ServiceVersion(config.ServiceVersion))
+	}
+	if config.DeploymentEnvironment != "" {
+		overrides = append(overrides, semconv.DeploymentEnvironmentName(config.DeploymentEnvironment))
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithAttributes(defaults...),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithOS(),
+		// 不使用WithProcess，以免process.command_args把命令行中的路径、端点或令牌发送到后端
+		resource.WithProcessPID(),
+		resource.WithProcessExecutableName(),
+		resource.WithProcessRuntimeName(),
+		resource.WithProcessRuntimeVersion(),
+		resource.WithProcessRuntimeDescription(),
+		resource.WithContainer(),
+		resource.WithFromEnv(),
+		resource.WithAttributes(overrides...),
+	)
+	if errors.Is(err, resource.ErrPartialResource) {
+		log.Printf("Some resource detectors failed: %v", err)
+		return res, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect resource: %w", err)
+	}
+	return res, nil
+}
+
+// buildVersion 从构建信息中获取服务版本
+//
+// 优先使用模块版本；本地构建（devel）时使用VCS修订号，工作区有改动时追加-dirty。
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+
+	var revision string
+	var modified bool
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision == "" {
+		return ""
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if modified {
+		revision += "-dirty"
+	}
+	return revision
+}
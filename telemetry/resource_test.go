@@ -0,0 +1,40 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+func TestNewResourceOmitsCommandLine(t *testing.T) {
+	res, err := newResource(context.Background(), Config{ServiceName: "resource-test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attrs := res.Set()
+	for _, key := range []attribute.Key{
+		semconv.ProcessCommandArgsKey,
+		semconv.ProcessCommandLineKey,
+		semconv.ProcessCommandKey,
+		semconv.ProcessExecutablePathKey,
+	} {
+		if value, ok := attrs.Value(key); ok {
+			t.Errorf("resource has %s = %s", key, value.Emit())
+		}
+	}
+	for _, key := range []attribute.Key{
+		semconv.ProcessPIDKey,
+		semconv.ProcessExecutableNameKey,
+		semconv.ProcessRuntimeNameKey,
+	} {
+		if _, ok := attrs.Value(key); !ok {
+			t.Errorf("resource is missing %s", key)
+		}
+	}
+	if value, _ := attrs.Value(semconv.ServiceNameKey); value.AsString() != "resource-test" {
+		t.Errorf("service.name = %q, want resource-test", value.AsString())
+	}
+}
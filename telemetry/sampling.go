@@ -0,0 +1,188 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	trace2 "go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// maxPendingTraces 同时缓存的未采样trace上限，超过后新的未采样trace直接丢弃
+	maxPendingTraces = 4096
+	// maxPendingSpansPerTrace 每个未采样trace最多缓存的span数量
+	maxPendingSpansPerTrace = 1024
+)
+
+// newSampler 根据OTEL_TRACES_SAMPLER规范的名称和参数创建sampler
+//
+// 与规范一致，不支持的名称回退为默认的parentbased_always_on，非法的比例回退为1.0，并记录警告。
+func newSampler(name, arg string) trace.Sampler {
+	ratio := func() float64 {
+		if arg == "" {
+			return 1.0
+		}
+		value, err := strconv.ParseFloat(arg, 64)
+		if err != nil || value < 0 || value > 1 {
+			log.Printf("Ignoring invalid sampler ratio: %q", arg)
+			return 1.0
+		}
+		return value
+	}
+
+	switch name {
+	case "", "parentbased_always_on":
+		return trace.ParentBased(trace.AlwaysSample())
+	case "always_on":
+		return trace.AlwaysSample()
+	case "always_off":
+		return trace.NeverSample()
+	case "parentbased_always_off":
+		return trace.ParentBased(trace.NeverSample())
+	case "traceidratio":
+		return trace.TraceIDRatioBased(ratio())
+	case "parentbased_traceidratio":
+		return trace.ParentBased(trace.TraceIDRatioBased(ratio()))
+	default:
+		log.Printf("Ignoring unsupported sampler: %q", name)
+		return trace.ParentBased(trace.AlwaysSample())
+	}
+}
+
+// recordOnlySampler 将内部sampler的Drop决策降级为RecordOnly
+//
+// 未采样的span仍会被记录并交给errorKeepingProcessor，以便在trace失败时补发。
+type recordOnlySampler struct {
+	inner trace.Sampler
+}
+
+// ShouldSample 实现trace.Sampler接口
+func (s recordOnlySampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	result := s.inner.ShouldSample(p)
+	if result.Decision == trace.Drop {
+		result.Decision = trace.RecordOnly
+	}
+	return result
+}
+
+// Description 实现trace.Sampler接口
+func (s recordOnlySampler) Description() string {
+	return fmt.Sprintf("RecordOnly{%s}", s.inner.Description())
+}
+
+// pendingTrace 尚未决定是否导出的未采样trace
+type pendingTrace struct {
+	spans []trace.ReadOnlySpan
+	keep  bool
+}
+
+// errorKeepingProcessor 保留包含失败span的完整trace
+//
+// 已采样的span直接交给下游processor；未采样的span按trace缓存，一旦该trace中有span失败，
+// 就将已缓存和后续的span全部导出；本地根span结束时仍未失败的trace被丢弃。
+type errorKeepingProcessor struct {
+	next trace.SpanProcessor
+
+	mu     sync.Mutex
+	traces map[trace2.TraceID]*pendingTrace
+}
+
+// newErrorKeepingProcessor 创建errorKeepingProcessor
+func newErrorKeepingProcessor(next trace.SpanProcessor) *errorKeepingProcessor {
+	return &errorKeepingProcessor{
+		next:   next,
+		traces: make(map[trace2.TraceID]*pendingTrace),
+	}
+}
+
+// OnStart 实现trace.SpanProcessor接口
+func (p *errorKeepingProcessor) OnStart(ctx context.Context, s trace.ReadWriteSpan) {
+	p.next.OnStart(ctx, s)
+}
+
+// OnEnd 实现trace.SpanProcessor接口
+func (p *errorKeepingProcessor) OnEnd(s trace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		p.next.OnEnd(s)
+		return
+	}
+
+	traceID := s.SpanContext().TraceID()
+	isLocalRoot := !s.Parent().IsValid() || s.Parent().IsRemote()
+
+	var flush []trace.ReadOnlySpan
+
+	p.mu.Lock()
+	pending, ok := p.traces[traceID]
+	if !ok {
+		if len(p.traces) >= maxPendingTraces && !spanFailed(s) {
+			p.mu.Unlock()
+			return
+		}
+		pending = &pendingTrace{}
+		p.traces[traceID] = pending
+	}
+
+	switch {
+	case pending.keep:
+		flush = []trace.ReadOnlySpan{s}
+	case spanFailed(s):
+		pending.keep = true
+		flush = append(pending.spans, s)
+		pending.spans = nil
+	case len(pending.spans) < maxPendingSpansPerTrace:
+		pending.spans = append(pending.spans, s)
+	}
+
+	if isLocalRoot {
+		delete(p.traces, traceID)
+	}
+	p.mu.Unlock()
+
+	for _, span := range flush {
+		p.next.OnEnd(sampledSpan{span})
+	}
+}
+
+// sampledSpan 将span标记为已采样，下游的批处理/同步processor只导出已采样的span
+type sampledSpan struct {
+	trace.ReadOnlySpan
+}
+
+// SpanContext 返回带有sampled标志的SpanContext
+func (s sampledSpan) SpanContext() trace2.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
+
+// Shutdown 实现trace.SpanProcessor接口
+func (p *errorKeepingProcessor) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	p.traces = make(map[trace2.TraceID]*pendingTrace)
+	p.mu.Unlock()
+	return p.next.Shutdown(ctx)
+}
+
+// ForceFlush 实现trace.SpanProcessor接口
+func (p *errorKeepingProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// spanFailed 判断span是否失败：状态为Error或记录了exception事件
+func spanFailed(s trace.ReadOnlySpan) bool {
+	if s.Status().Code == codes.Error {
+		return true
+	}
+	for _, event := range s.Events() {
+		if event.Name == semconv.ExceptionEventName {
+			return true
+		}
+	}
+	return false
+}
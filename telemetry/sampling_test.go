@@ -0,0 +1,191 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	trace2 "go.opentelemetry.io/otel/trace"
+)
+
+// captureLog 在测试期间捕获标准日志输出
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer, flags := log.Writer(), log.Flags()
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	t.Cleanup(func() {
+		log.SetOutput(writer)
+		log.SetFlags(flags)
+	})
+	return &buf
+}
+
+func TestNewSampler(t *testing.T) {
+	defaultSampler := trace.ParentBased(trace.AlwaysSample())
+
+	tests := []struct {
+		name    string
+		sampler string
+		arg     string
+		want    trace.Sampler
+		warning string
+	}{
+		{name: "default", want: defaultSampler},
+		{name: "parentbased_always_on", sampler: "parentbased_always_on", want: defaultSampler},
+		{name: "always_on", sampler: "always_on", want: trace.AlwaysSample()},
+		{name: "always_off", sampler: "always_off", want: trace.NeverSample()},
+		{name: "parentbased_always_off", sampler: "parentbased_always_off", want: trace.ParentBased(trace.NeverSample())},
+		{name: "traceidratio", sampler: "traceidratio", arg: "0.25", want: trace.TraceIDRatioBased(0.25)},
+		{name: "traceidratio without arg", sampler: "traceidratio", want: trace.TraceIDRatioBased(1.0)},
+		{name: "parentbased_traceidratio", sampler: "parentbased_traceidratio", arg: "0.1", want: trace.ParentBased(trace.TraceIDRatioBased(0.1))},
+		{name: "invalid ratio", sampler: "traceidratio", arg: "abc", want: trace.TraceIDRatioBased(1.0), warning: "invalid sampler ratio"},
+		{name: "ratio out of range", sampler: "parentbased_traceidratio", arg: "1.5", want: trace.ParentBased(trace.TraceIDRatioBased(1.0)), warning: "invalid sampler ratio"},
+		{name: "unknown sampler", sampler: "jaeger_remote", want: defaultSampler, warning: "unsupported sampler"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLog(t)
+
+			got := newSampler(tt.sampler, tt.arg)
+			if got.Description() != tt.want.Description() {
+				t.Errorf("newSampler(%q, %q) = %s, want %s", tt.sampler, tt.arg, got.Description(), tt.want.Description())
+			}
+			if tt.warning == "" && logs.Len() > 0 {
+				t.Errorf("unexpected warning: %s", logs)
+			}
+			if !strings.Contains(logs.String(), tt.warning) {
+				t.Errorf("warning = %q, want %q", logs, tt.warning)
+			}
+		})
+	}
+}
+
+func TestSamplerFromEnv(t *testing.T) {
+	t.Setenv("OTEL_TRACES_SAMPLER", "parentbased_traceidratio")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "0.1")
+
+	config := GetConfigFromEnv()
+	want := trace.ParentBased(trace.TraceIDRatioBased(0.1)).Description()
+	if got := newSampler(config.Sampler, config.SamplerArg).Description(); got != want {
+		t.Errorf("sampler = %s, want %s", got, want)
+	}
+}
+
+func TestSetupUnknownSampler(t *testing.T) {
+	logs := captureLog(t)
+	setupForTest(t, Config{Exporters: []ExporterSpec{{Type: ExporterNone}}, Sampler: "unknown"})
+	if !strings.Contains(logs.String(), `unsupported sampler: "unknown"`) {
+		t.Errorf("warning = %q", logs)
+	}
+}
+
+func TestRecordOnlySampler(t *testing.T) {
+	tests := []struct {
+		name  string
+		inner trace.Sampler
+		want  trace.SamplingDecision
+	}{
+		{name: "drop becomes record only", inner: trace.NeverSample(), want: trace.RecordOnly},
+		{name: "sampled stays sampled", inner: trace.AlwaysSample(), want: trace.RecordAndSample},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sampler := recordOnlySampler{inner: tt.inner}
+			result := sampler.ShouldSample(trace.SamplingParameters{ParentContext: context.Background(), Name: "span"})
+			if result.Decision != tt.want {
+				t.Errorf("decision = %v, want %v", result.Decision, tt.want)
+			}
+			if want := "RecordOnly{" + tt.inner.Description() + "}"; sampler.Description() != want {
+				t.Errorf("description = %s, want %s", sampler.Description(), want)
+			}
+		})
+	}
+}
+
+func TestErrorKeepingProcessor(t *testing.T) {
+	tests := []struct {
+		name string
+		// fail 使名为failed的子span失败，为nil时trace中没有失败的span
+		fail func(span trace2.Span)
+		want []string
+	}{
+		{name: "unsampled trace without errors is dropped"},
+		{
+			name: "error status keeps the whole trace",
+			fail: func(span trace2.Span) { span.SetStatus(codes.Error, "failed") },
+			want: []string{"first", "failed", "after", "root"},
+		},
+		{
+			name: "exception event keeps the whole trace",
+			fail: func(span trace2.Span) { span.RecordError(errors.New("failed")) },
+			want: []string{"first", "failed", "after", "root"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewInMemoryExporter()
+			processor := newErrorKeepingProcessor(trace.NewSimpleSpanProcessor(recorder))
+			provider := trace.NewTracerProvider(
+				trace.WithSampler(recordOnlySampler{inner: trace.NeverSample()}),
+				trace.WithSpanProcessor(processor),
+			)
+			t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+			tracer := provider.Tracer("test")
+
+			ctx, root := tracer.Start(context.Background(), "root")
+			for _, name := range []string{"first", "failed", "after"} {
+				_, span := tracer.Start(ctx, name)
+				if name == "failed" && tt.fail != nil {
+					tt.fail(span)
+				}
+				span.End()
+			}
+			root.End()
+
+			var got []string
+			for _, span := range recorder.GetSpans() {
+				if !span.SpanContext.IsSampled() {
+					t.Errorf("span %s exported without the sampled flag", span.Name)
+				}
+				got = append(got, span.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("exported spans = %v, want %v", got, tt.want)
+			}
+
+			// 根span结束后trace不再缓存
+			if n := len(processor.traces); n != 0 {
+				t.Errorf("%d traces still pending", n)
+			}
+		})
+	}
+}
+
+func TestErrorKeepingProcessorSampledSpans(t *testing.T) {
+	recorder := tracetest.NewInMemoryExporter()
+	provider := trace.NewTracerProvider(
+		trace.WithSampler(recordOnlySampler{inner: trace.AlwaysSample()}),
+		trace.WithSpanProcessor(newErrorKeepingProcessor(trace.NewSimpleSpanProcessor(recorder))),
+	)
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	_, span := provider.Tracer("test").Start(context.Background(), "sampled")
+	span.End()
+
+	if spans := recorder.GetSpans(); len(spans) != 1 || spans[0].Name != "sampled" {
+		t.Errorf("exported spans = %v, want the sampled span", spans)
+	}
+}
@@ -0,0 +1,110 @@
+package telemetry
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// SemconvStability 定义导出的GenAI语义约定版本，取值同OTEL_SEMCONV_STABILITY_OPT_IN
+//
+// 代码中始终使用最新的语义约定记录属性，导出前再按此设置转换。零值与SemconvStabilityLatest相同。
+type SemconvStability string
+
+const (
+	// SemconvStabilityLatest 只输出代码中记录的最新属性（如gen_ai.provider.name）
+	SemconvStabilityLatest SemconvStability = "gen_ai_latest_experimental"
+	// SemconvStabilityDup 同时输出旧版和最新版属性，用于迁移期间
+	SemconvStabilityDup SemconvStability = "gen_ai_latest_experimental/dup"
+)
+
+// legacyAttributeKeys 最新属性到旧版属性的映射，只包含含义相同、仅改名的属性
+var legacyAttributeKeys = map[attribute.Key]attribute.Key{
+	semconv.GenAIProviderNameKey:      "gen_ai.system",
+	semconv.GenAIUsageInputTokensKey:  "gen_ai.usage.prompt_tokens",
+	semconv.GenAIUsageOutputTokensKey: "gen_ai.usage.completion_tokens",
+	semconv.GenAIRequestSeedKey:       "gen_ai.openai.request.seed",
+}
+
+// legacyProviderNames gen_ai.provider.name中改名的取值在gen_ai.system中的旧值
+var legacyProviderNames = map[string]string{
+	"azure.ai.inference": "az.ai.inference",
+	"azure.ai.openai":    "az.ai.openai",
+	"gcp.gemini":         "gemini",
+	"gcp.vertex_ai":      "vertex_ai",
+}
+
+// parseSemconvStability 解析OTEL_SEMCONV_STABILITY_OPT_IN，只关心GenAI相关的取值
+func parseSemconvStability(raw string) SemconvStability {
+	for _, value := range strings.Split(raw, ",") {
+		if SemconvStability(strings.TrimSpace(value)) == SemconvStabilityDup {
+			return SemconvStabilityDup
+		}
+	}
+	return SemconvStabilityLatest
+}
+
+// translateAttributes 在最新属性前追加对应的旧版属性
+func translateAttributes(attrs []attribute.KeyValue) []attribute.KeyValue {
+	result := make([]attribute.KeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		legacyKey, ok := legacyAttributeKeys[kv.Key]
+		if !ok {
+			result = append(result, kv)
+			continue
+		}
+
+		value := kv.Value
+		if kv.Key == semconv.GenAIProviderNameKey {
+			if legacy, ok := legacyProviderNames[value.AsString()]; ok {
+				value = attribute.StringValue(legacy)
+			}
+		}
+		result = append(result, attribute.KeyValue{Key: legacyKey, Value: value}, kv)
+	}
+	return result
+}
+
+// semconvExporter 在导出前为span追加旧版属性
+type semconvExporter struct {
+	next trace.SpanExporter
+}
+
+// newSemconvExporter 创建转换属性的导出器，只有dup需要转换，其他情况直接返回next
+func newSemconvExporter(next trace.SpanExporter, stability SemconvStability) trace.SpanExporter {
+	if stability != SemconvStabilityDup {
+		return next
+	}
+	return &semconvExporter{next: next}
+}
+
+// ExportSpans 实现trace.SpanExporter接口
+func (e *semconvExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	translated := make([]trace.ReadOnlySpan, len(spans))
+	for i, span := range spans {
+		translated[i] = translatedSpan{
+			ReadOnlySpan: span,
+			attrs:        translateAttributes(span.Attributes()),
+		}
+	}
+	return e.next.ExportSpans(ctx, translated)
+}
+
+// Shutdown 实现trace.SpanExporter接口
+func (e *semconvExporter) Shutdown(ctx context.Context) error {
+	return e.next.Shutdown(ctx)
+}
+
+// translatedSpan 使用转换后属性的span
+type translatedSpan struct {
+	trace.ReadOnlySpan
+	attrs []attribute.KeyValue
+}
+
+// Attributes 返回转换后的属性
+func (s translatedSpan) Attributes() []attribute.KeyValue {
+	return s.attrs
+}
@@ -0,0 +1,84 @@
+package telemetry
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestParseSemconvStability(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want SemconvStability
+	}{
+		{raw: "", want: SemconvStabilityLatest},
+		{raw: "http", want: SemconvStabilityLatest},
+		{raw: "gen_ai_latest_experimental", want: SemconvStabilityLatest},
+		{raw: "gen_ai_legacy", want: SemconvStabilityLatest},
+		{raw: "http, gen_ai_latest_experimental/dup", want: SemconvStabilityDup},
+		{raw: "gen_ai_latest_experimental,gen_ai_latest_experimental/dup", want: SemconvStabilityDup},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := parseSemconvStability(tt.raw); got != tt.want {
+				t.Errorf("parseSemconvStability(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSemconvExporter(t *testing.T) {
+	attrs := []attribute.KeyValue{
+		attribute.String("gen_ai.provider.name", "azure.ai.openai"),
+		attribute.Int("gen_ai.usage.input_tokens", 10),
+		attribute.Int("gen_ai.usage.output_tokens", 5),
+		attribute.String("gen_ai.output.type", "json"),
+		attribute.String("gen_ai.input.messages", `[{"role":"user"}]`),
+	}
+
+	tests := []struct {
+		name      string
+		stability SemconvStability
+		want      []attribute.KeyValue
+	}{
+		{name: "unset", want: attrs},
+		{name: "latest", stability: SemconvStabilityLatest, want: attrs},
+		{
+			name:      "dup",
+			stability: SemconvStabilityDup,
+			want: []attribute.KeyValue{
+				attribute.String("gen_ai.system", "az.ai.openai"),
+				attrs[0],
+				attribute.Int("gen_ai.usage.prompt_tokens", 10),
+				attrs[1],
+				attribute.Int("gen_ai.usage.completion_tokens", 5),
+				attrs[2],
+				attrs[3],
+				attrs[4],
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewInMemoryExporter()
+			exporter := newSemconvExporter(recorder, tt.stability)
+			spans := tracetest.SpanStubs{{Name: "chat", Attributes: attrs}}.Snapshots()
+			if err := exporter.ExportSpans(context.Background(), spans); err != nil {
+				t.Fatal(err)
+			}
+
+			got := recorder.GetSpans()
+			if len(got) != 1 {
+				t.Fatalf("exported %d spans, want 1", len(got))
+			}
+			if !reflect.DeepEqual(got[0].Attributes, tt.want) {
+				t.Errorf("attributes = %v, want %v", got[0].Attributes, tt.want)
+			}
+		})
+	}
+}
@@ -2,18 +2,22 @@ package telemetry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/url"
-	"os"
+	"strings"
+	"time"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
-	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/log/global"
 	"go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	trace2 "go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/credentials"
 )
 
 // ExporterType 定义导出器类型
@@ -22,14 +26,123 @@ type ExporterType string
 const (
 	ExporterConsole ExporterType = "console"
 	ExporterHTTP    ExporterType = "http"
+	ExporterGRPC    ExporterType = "grpc"
 	ExporterAuto    ExporterType = "auto"
+	ExporterFile    ExporterType = "file"
+	ExporterNone    ExporterType = "none"
+	// ExporterPrometheus 仅用于metric，通过HTTP提供Prometheus抓取端点
+	ExporterPrometheus ExporterType = "prometheus"
+)
+
+// ProcessorType 定义span processor类型
+type ProcessorType string
+
+const (
+	// ProcessorBatch 批量异步导出（默认）
+	ProcessorBatch ProcessorType = "batch"
+	// ProcessorSimple 每个span结束时同步导出，适合本地调试
+	ProcessorSimple ProcessorType = "simple"
+)
+
+// ExporterSpec 定义一个trace导出器及其span processor
+type ExporterSpec struct {
+	Type      ExporterType
+	Processor ProcessorType
+}
+
+const (
+	// DefaultHTTPEndpoint OTLP/HTTP默认端点
+	DefaultHTTPEndpoint = "http://localhost:4318"
+	// DefaultGRPCEndpoint OTLP/gRPC默认端点
+	DefaultGRPCEndpoint = "http://localhost:4317"
+	// DefaultShutdownTimeout InitTracerWithConfig返回的cleanup函数等待数据导出的最长时间
+	DefaultShutdownTimeout = 10 * time.Second
 )
 
 // Config 定义telemetry配置
 type Config struct {
+	// ExporterType trace导出器类型，ExporterNone表示不导出trace
 	ExporterType ExporterType
-	Endpoint     string
-	ServiceName  string
+	// Exporters 同时使用的多个trace导出器，非空时优先于ExporterType
+	Exporters []ExporterSpec
+	// Endpoint OTLP基础端点，HTTP导出器会在其路径后追加/v1/traces
+	Endpoint string
+	// TracesEndpoint trace专用的完整端点，设置后优先于Endpoint且路径按原样使用
+	TracesEndpoint string
+	// MetricsEndpoint metric专用的完整端点，设置后优先于Endpoint且路径按原样使用
+	MetricsEndpoint string
+	// MetricsExporterType metric导出器类型，ExporterNone表示不导出metric；
+	// 为空时只在ExporterType为console时输出到console，其他情况不导出
+	MetricsExporterType ExporterType
+	// LogsEndpoint 日志专用的完整端点，设置后优先于Endpoint且路径按原样使用
+	LogsEndpoint string
+	// LogsExporterType 日志导出器类型，ExporterNone表示不导出日志；
+	// 为空时只在启用EmitEvents且ExporterType为console时输出到console，其他情况不导出
+	LogsExporterType ExporterType
+	// EmitEvents 为true时GenAI消息内容以日志事件发送，而不是写入span属性
+	EmitEvents bool
+	// FilePath 文件导出器的输出路径，为空时使用DefaultFilePath
+	FilePath string
+	// FileMaxBytes 文件导出器单个文件的最大字节数，超过后轮转，0表示使用DefaultFileMaxBytes
+	FileMaxBytes int64
+	// Sampler 采样器名称，取值同OTEL_TRACES_SAMPLER，为空时使用parentbased_always_on
+	Sampler string
+	// SamplerArg 采样器参数，取值同OTEL_TRACES_SAMPLER_ARG
+	SamplerArg string
+	// KeepErrorTraces 为true时，即使trace未被采样，只要其中有span失败就导出整个trace
+	KeepErrorTraces bool
+	// SemconvStability 导出的GenAI语义约定版本，取值同OTEL_SEMCONV_STABILITY_OPT_IN，默认只输出最新属性
+	SemconvStability SemconvStability
+	// ValidateSemconv 为true时检查结束的span是否符合GenAI语义约定，违规写入日志
+	ValidateSemconv bool
+	// AttributeValueLengthLimit span字符串属性的最大字符数，JSON属性截断后仍保持合法，0表示不限制
+	AttributeValueLengthLimit int
+	// OpenInference 为true时导出的span额外带上OpenInference属性（llm.*、openinference.span.kind等）
+	OpenInference bool
+	// ContentCapture GenAI消息内容采集策略，为空时不记录消息
+	ContentCapture ContentCapture
+	// Redactor 完整采集消息时使用的脱敏器，为nil时使用DefaultRedactor
+	Redactor Redactor
+	// PrometheusAddr 非空时在该地址的/metrics上提供Prometheus抓取端点
+	PrometheusAddr string
+	// ServiceName service.name，为空时使用OTEL_RESOURCE_ATTRIBUTES中的值或DefaultServiceName
+	ServiceName string
+	// ServiceVersion service.version，为空时从构建信息获取
+	ServiceVersion string
+	// DeploymentEnvironment deployment.environment.name，如production、staging
+	DeploymentEnvironment string
+	// Headers 随每个OTLP请求发送的头部，例如Authorization
+	Headers map[string]string
+	// TLS OTLP导出器的TLS配置，仅在https端点上生效
+	TLS TLSConfig
+	// Timeout 单次导出的超时时间，0表示使用SDK默认值
+	Timeout time.Duration
+	// Compression 导出压缩方式 (gzip/none)
+	Compression string
+	// Traces trace导出器专用的OTLP设置，优先于上面的通用设置
+	Traces SignalConfig
+	// Metrics metric导出器专用的OTLP设置，优先于上面的通用设置
+	Metrics SignalConfig
+	// Logs 日志导出器专用的OTLP设置，优先于上面的通用设置
+	Logs SignalConfig
+	// Propagators 跨进程传播使用的propagator，取值同OTEL_PROPAGATORS，为空时使用tracecontext和baggage
+	Propagators []string
+	// Disabled 为true时不安装任何provider，所有span均为no-op
+	Disabled bool
+}
+
+// SignalConfig 定义单个信号（trace、metric或日志）专用的OTLP设置
+//
+// 对应OTEL_EXPORTER_OTLP_{TRACES,METRICS,LOGS}_*环境变量，非零字段覆盖Config中的通用设置。
+type SignalConfig struct {
+	// Headers 随该信号的OTLP请求发送的头部
+	Headers map[string]string
+	// TLS 该信号的TLS配置，非空字段覆盖通用TLS配置
+	TLS TLSConfig
+	// Timeout 该信号单次导出的超时时间
+	Timeout time.Duration
+	// Compression 该信号的导出压缩方式 (gzip/none)
+	Compression string
 }
 
 // InitTracer 初始化OpenTelemetry tracer
@@ -41,60 +154,207 @@ func InitTracer() func() {
 }
 
 // InitTracerWithConfig 使用配置初始化OpenTelemetry tracer
+//
+// 初始化失败时直接退出进程，关闭时的错误只记录日志。需要自行处理错误时使用Setup。
 func InitTracerWithConfig(config Config) func() {
-	if config.ServiceName == "" {
-		config.ServiceName = "gen-ai-example"
+	shutdown, err := Setup(context.Background(), config)
+	if err != nil {
+		log.Fatalf("Failed to initialize telemetry: %v", err)
+	}
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultShutdownTimeout)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			log.Printf("Error shutting down telemetry: %v", err)
+		}
 	}
+}
+
+// Setup 校验配置并安装全局的tracer、meter和logger provider
+//
+// 返回的shutdown函数依次关闭所有provider，在ctx的截止时间内刷新未导出的数据，
+// 并返回合并后的错误。初始化失败时已创建的组件会被关闭，全局provider保持不变。
+func Setup(ctx context.Context, config Config) (shutdown func(context.Context) error, err error) {
+	if config.Disabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid telemetry config: %w", err)
+	}
+
+	// auto模式先探测collector，不可达时直接使用console
+	config = resolveAutoExporter(ctx, config)
 
-	var exporter trace.SpanExporter
-	var err error
+	// 记录已创建的组件，失败时逆序关闭
+	var shutdownFuncs []func(context.Context) error
+	shutdownAll := func(ctx context.Context) error {
+		var errs []error
+		for i := len(shutdownFuncs) - 1; i >= 0; i-- {
+			errs = append(errs, shutdownFuncs[i](ctx))
+		}
+		shutdownFuncs = nil
+		return errors.Join(errs...)
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, shutdownAll(ctx))
+		}
+	}()
 
 	// 根据配置创建导出器
-	switch config.ExporterType {
-	case ExporterHTTP:
-		if config.Endpoint == "" {
-			config.Endpoint = "http://localhost:4318"
+	specs := config.exporterSpecs()
+	exporters := make([]trace.SpanExporter, 0, len(specs))
+	for _, spec := range specs {
+		exporter, err := createSpanExporter(ctx, config, spec.Type)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s exporter: %w", spec.Type, err)
 		}
-		exporter, err = createHTTPOutputExporter(config.Endpoint)
-	case ExporterAuto:
-		if config.Endpoint == "" {
-			config.Endpoint = "http://localhost:4318"
+		// 截断在最后执行，以便同时作用于转换和追加的属性
+		exporter = newLimitExporter(exporter, config.AttributeValueLengthLimit)
+		exporter = newSemconvExporter(exporter, config.SemconvStability)
+		if config.OpenInference {
+			// 需要在语义约定转换之前执行，以读取最新的gen_ai.*属性
+			exporter = newOpenInferenceExporter(exporter)
 		}
-		// 优先尝试HTTP，失败后回退到console
-		if exporter, err = createHTTPOutputExporter(config.Endpoint); err != nil {
-			log.Printf("Failed to create HTTP exporter, falling back to console: %v", err)
-			exporter, err = createConsoleExporter()
+		exporters = append(exporters, exporter)
+		shutdownFuncs = append(shutdownFuncs, exporter.Shutdown)
+	}
+
+	res, err := newResource(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	sampler := newSampler(config.Sampler, config.SamplerArg)
+
+	if config.KeepErrorTraces {
+		// 未采样的span仍被记录，trace失败时整体导出
+		sampler = recordOnlySampler{inner: sampler}
+	}
+
+	// 设置了长度上限时由导出器截断，关闭SDK自带的截断；其余情况及其他限制仍取自环境变量
+	limits := trace.NewSpanLimits()
+	if config.AttributeValueLengthLimit > 0 {
+		limits.AttributeValueLengthLimit = -1
+	}
+
+	opts := []trace.TracerProviderOption{
+		trace.WithSampler(sampler),
+		trace.WithResource(res),
+		trace.WithRawSpanLimits(limits),
+	}
+	for i, exporter := range exporters {
+		var processor trace.SpanProcessor
+		if specs[i].Processor == ProcessorSimple {
+			processor = trace.NewSimpleSpanProcessor(exporter)
+		} else {
+			processor = trace.NewBatchSpanProcessor(exporter)
 		}
-	case ExporterConsole, "":
-		exporter, err = createConsoleExporter()
-	default:
-		log.Fatalf("Unsupported exporter type: %s", config.ExporterType)
+		if config.KeepErrorTraces {
+			processor = newErrorKeepingProcessor(processor)
+		}
+		opts = append(opts, trace.WithSpanProcessor(processor))
 	}
+	if config.ValidateSemconv {
+		opts = append(opts, trace.WithSpanProcessor(NewConformanceProcessor(nil)))
+	}
+
+	// 创建trace provider，其关闭时会一并关闭各导出器
+	tp := trace.NewTracerProvider(opts...)
+	shutdownFuncs = []func(context.Context) error{tp.Shutdown}
 
+	// 创建meter provider
+	mp, metricsServer, err := newMeterProvider(ctx, config, res)
 	if err != nil {
-		log.Fatalf("Failed to create exporter: %v", err)
+		return nil, fmt.Errorf("failed to create meter provider: %w", err)
+	}
+	if mp != nil {
+		shutdownFuncs = append(shutdownFuncs, mp.Shutdown)
+	}
+	if metricsServer != nil {
+		shutdownFuncs = append(shutdownFuncs, metricsServer.Shutdown)
 	}
 
-	// 创建trace provider
-	tp := trace.NewTracerProvider(
-		trace.WithBatcher(exporter),
-		trace.WithResource(resource.NewWithAttributes(
-			semconv.SchemaURL,
-			semconv.ServiceNameKey.String(config.ServiceName),
-		)),
-	)
+	// 创建logger provider
+	lp, err := newLoggerProvider(ctx, config, res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create logger provider: %w", err)
+	}
+	if lp != nil {
+		shutdownFuncs = append(shutdownFuncs, lp.Shutdown)
+	} else if config.EmitEvents {
+		log.Printf("GenAI events are enabled but no logs exporter is configured, set OTEL_LOGS_EXPORTER to export them")
+	}
 
-	// 设置全局trace provider
+	propagator := newPropagator(config.Propagators)
+
+	// 全部创建成功后再设置全局provider
 	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagator)
+	if mp != nil {
+		otel.SetMeterProvider(mp)
+	}
+	if lp != nil {
+		global.SetLoggerProvider(lp)
+	}
+	emitEvents.Store(config.EmitEvents)
+	setContentPolicy(config.ContentCapture, config.Redactor)
 
-	// 返回cleanup函数
-	return func() {
-		if err := tp.Shutdown(context.Background()); err != nil {
-			log.Printf("Error shutting down tracer provider: %v", err)
+	return shutdownAll, nil
+}
+
+// exporterSpecs 返回需要创建的trace导出器列表，ExporterNone不创建导出器
+func (c Config) exporterSpecs() []ExporterSpec {
+	specs := c.Exporters
+	if len(specs) == 0 {
+		specs = []ExporterSpec{{Type: c.ExporterType, Processor: ProcessorBatch}}
+	}
+
+	result := make([]ExporterSpec, 0, len(specs))
+	for _, spec := range specs {
+		if spec.Type != ExporterNone {
+			result = append(result, spec)
 		}
-		if err := exporter.Shutdown(context.Background()); err != nil {
-			log.Printf("Error shutting down exporter: %v", err)
+	}
+	return result
+}
+
+// createSpanExporter 根据导出器类型创建span导出器
+func createSpanExporter(ctx context.Context, config Config, exporterType ExporterType) (trace.SpanExporter, error) {
+	switch exporterType {
+	case ExporterHTTP:
+		if config.Endpoint == "" {
+			config.Endpoint = DefaultHTTPEndpoint
+		}
+		return createHTTPOutputExporter(ctx, config)
+	case ExporterGRPC:
+		if config.Endpoint == "" {
+			config.Endpoint = DefaultGRPCEndpoint
+		}
+		return createGRPCOutputExporter(ctx, config)
+	case ExporterAuto:
+		if config.Endpoint == "" {
+			config.Endpoint = DefaultHTTPEndpoint
+		}
+		// 优先尝试HTTP，失败后回退到console；运行时持续导出失败也会切换到console
+		console, err := createConsoleExporter()
+		if err != nil {
+			return nil, err
 		}
+		exporter, err := createHTTPOutputExporter(ctx, config)
+		if err != nil {
+			log.Printf("Failed to create HTTP exporter, falling back to console: %v", err)
+			return console, nil
+		}
+		return newAutoExporter(exporter, console), nil
+	case ExporterFile:
+		return createFileExporter(ctx, config)
+	case ExporterConsole, "":
+		return createConsoleExporter()
+	default:
+		return nil, fmt.Errorf("unsupported exporter type: %s", exporterType)
 	}
 }
 
@@ -106,57 +366,174 @@ func createConsoleExporter() (*stdouttrace.Exporter, error) {
 }
 
 // createHTTPOutputExporter 创建HTTP导出器
-func createHTTPOutputExporter(endpoint string) (trace.SpanExporter, error) {
-	// 验证endpoint URL
-	if _, err := url.Parse(endpoint); err != nil {
-		return nil, fmt.Errorf("invalid endpoint URL: %v", err)
+func createHTTPOutputExporter(ctx context.Context, config Config) (trace.SpanExporter, error) {
+	client, err := newHTTPTraceClient(config)
+	if err != nil {
+		return nil, err
 	}
+	return otlptrace.New(ctx, client)
+}
 
-	// 确保endpoint不包含路径和查询参数
-	parsedURL, err := url.Parse(endpoint)
+// newHTTPTraceClient 创建OTLP/HTTP trace客户端
+func newHTTPTraceClient(config Config) (otlptrace.Client, error) {
+	config = config.forSignal(config.Traces)
+	endpoint, err := config.tracesEndpoint()
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse endpoint URL: %v", err)
+		return nil, err
 	}
 
-	cleanEndpoint := parsedURL.Hostname()
-	if parsedURL.Port() != "" {
-		cleanEndpoint = cleanEndpoint + ":" + parsedURL.Port()
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(endpoint.host),
+		otlptracehttp.WithURLPath(endpoint.path),
+	}
+	if endpoint.secure {
+		tlsConfig, err := config.TLS.build()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, otlptracehttp.WithTLSClientConfig(tlsConfig))
+	} else {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if len(config.Headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(config.Headers))
+	}
+	if config.Timeout > 0 {
+		opts = append(opts, otlptracehttp.WithTimeout(config.Timeout))
+	}
+	if config.Compression == "gzip" {
+		opts = append(opts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
 	}
 
-	return otlptracehttp.New(context.Background(),
-		otlptracehttp.WithEndpoint(cleanEndpoint),
-		otlptracehttp.WithInsecure(),
-	)
+	return otlptracehttp.NewClient(opts...), nil
 }
 
-// GetTracer 获取tracer实例
-func GetTracer(name string) trace2.Tracer {
-	return otel.Tracer(name)
+// createGRPCOutputExporter 创建gRPC导出器
+func createGRPCOutputExporter(ctx context.Context, config Config) (trace.SpanExporter, error) {
+	client, err := newGRPCTraceClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return otlptrace.New(ctx, client)
 }
 
-// GetConfigFromEnv 从环境变量获取配置
-func GetConfigFromEnv() Config {
-	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
-	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+// newGRPCTraceClient 创建OTLP/gRPC trace客户端
+func newGRPCTraceClient(config Config) (otlptrace.Client, error) {
+	config = config.forSignal(config.Traces)
+	endpoint, err := config.tracesEndpoint()
+	if err != nil {
+		return nil, err
+	}
 
-	var exporterType ExporterType
-	switch os.Getenv("OTEL_TRACES_EXPORTER") {
-	case "otlp", "http":
-		exporterType = ExporterHTTP
-	case "console", "":
-		exporterType = ExporterConsole
-	default:
-		exporterType = ExporterType(os.Getenv("OTEL_TRACES_EXPORTER"))
+	opts := []otlptracegrpc.Option{
+		otlptracegrpc.WithEndpoint(endpoint.host),
+	}
+	if config.grpcSecure(endpoint) {
+		tlsConfig, err := config.TLS.build()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		opts = append(opts, otlptracegrpc.WithInsecure())
 	}
+	if len(config.Headers) > 0 {
+		opts = append(opts, otlptracegrpc.WithHeaders(config.Headers))
+	}
+	if config.Timeout > 0 {
+		opts = append(opts, otlptracegrpc.WithTimeout(config.Timeout))
+	}
+	if config.Compression == "gzip" {
+		opts = append(opts, otlptracegrpc.WithCompressor("gzip"))
+	}
+
+	return otlptracegrpc.NewClient(opts...), nil
+}
+
+// otlpEndpoint 解析后的OTLP端点
+type otlpEndpoint struct {
+	host   string
+	path   string
+	secure bool
+	// noScheme 端点中没有scheme
+	noScheme bool
+}
+
+// forSignal 返回用signal中的非零字段覆盖通用OTLP设置后的配置
+func (c Config) forSignal(signal SignalConfig) Config {
+	if signal.Headers != nil {
+		c.Headers = signal.Headers
+	}
+	c.TLS = c.TLS.merge(signal.TLS)
+	if signal.Timeout != 0 {
+		c.Timeout = signal.Timeout
+	}
+	if signal.Compression != "" {
+		c.Compression = signal.Compression
+	}
+	return c
+}
 
-	if endpoint != "" && exporterType != ExporterConsole {
-		// 如果设置了endpoint，默认使用HTTP导出器
-		exporterType = ExporterHTTP
+// grpcSecure 判断gRPC导出器是否启用TLS
+//
+// 端点带scheme时由scheme决定；没有scheme时按OTLP规范默认启用TLS，设置了TLS.Insecure时使用明文连接。
+func (c Config) grpcSecure(endpoint otlpEndpoint) bool {
+	if endpoint.noScheme {
+		return !c.TLS.Insecure
 	}
+	return endpoint.secure
+}
+
+// tracesEndpoint 解析trace导出使用的端点
+func (c Config) tracesEndpoint() (otlpEndpoint, error) {
+	return c.signalEndpoint(c.TracesEndpoint, "/v1/traces")
+}
 
-	return Config{
-		ExporterType: exporterType,
-		Endpoint:     endpoint,
-		ServiceName:  serviceName,
+// signalEndpoint 解析某个信号导出使用的端点
+//
+// 信号专用端点优先且路径按原样使用；否则使用Endpoint并在其路径后追加signalPath，
+// 以支持挂载在反向代理前缀下的collector。
+func (c Config) signalEndpoint(specific, signalPath string) (otlpEndpoint, error) {
+	if specific != "" {
+		return parseOTLPEndpoint(specific, "")
 	}
+	return parseOTLPEndpoint(c.Endpoint, signalPath)
+}
+
+// parseOTLPEndpoint 解析endpoint URL，返回host:port、URL路径以及是否使用TLS
+//
+// signalPath非空时追加到endpoint路径之后，为空时路径按原样使用。
+func parseOTLPEndpoint(endpoint string, signalPath string) (otlpEndpoint, error) {
+	// 没有scheme的端点（如localhost:4317）按http解析，gRPC导出器再根据TLS.Insecure决定是否启用TLS
+	noScheme := !strings.Contains(endpoint, "://")
+	if noScheme {
+		endpoint = "http://" + endpoint
+	}
+
+	parsedURL, err := url.Parse(endpoint)
+	if err != nil {
+		return otlpEndpoint{}, fmt.Errorf("invalid endpoint URL: %v", err)
+	}
+	if parsedURL.Hostname() == "" {
+		return otlpEndpoint{}, fmt.Errorf("invalid endpoint URL: missing host in %q", endpoint)
+	}
+
+	path := parsedURL.Path
+	if signalPath != "" {
+		path = strings.TrimSuffix(path, "/") + signalPath
+	} else if path == "" {
+		path = "/"
+	}
+
+	return otlpEndpoint{
+		host:     parsedURL.Host,
+		path:     path,
+		secure:   parsedURL.Scheme == "https",
+		noScheme: noScheme,
+	}, nil
+}
+
+// GetTracer 获取tracer实例
+func GetTracer(name string) trace2.Tracer {
+	return otel.Tracer(name)
 }
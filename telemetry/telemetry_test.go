@@ -0,0 +1,285 @@
+package telemetry
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// setupForTest 调用Setup并在测试结束时关闭provider、恢复全局的tracer provider和propagator
+func setupForTest(t *testing.T, config Config) {
+	t.Helper()
+
+	previousProvider := otel.GetTracerProvider()
+	previousPropagator := otel.GetTextMapPropagator()
+	shutdown, err := Setup(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Setup() error: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := shutdown(context.Background()); err != nil {
+			t.Errorf("shutdown error: %v", err)
+		}
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+}
+
+// newOTLPReceiver 启动接收OTLP/HTTP trace请求的测试服务器，返回服务器和已收到的span
+func newOTLPReceiver(t *testing.T) (*httptest.Server, func() []*tracepb.Span) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var spans []*tracepb.Span
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			reader, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			body = reader
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var request coltracepb.ExportTraceServiceRequest
+		if err := proto.Unmarshal(data, &request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		for _, rs := range request.GetResourceSpans() {
+			for _, ss := range rs.GetScopeSpans() {
+				spans = append(spans, ss.GetSpans()...)
+			}
+		}
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []*tracepb.Span {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*tracepb.Span(nil), spans...)
+	}
+}
+
+// openFiles 返回当前进程打开的、指向path的文件描述符数量
+func openFiles(t *testing.T, path string) int {
+	t.Helper()
+
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skipf("cannot list open files: %v", err)
+	}
+	count := 0
+	for _, entry := range entries {
+		if target, err := os.Readlink(filepath.Join("/proc/self/fd", entry.Name())); err == nil && target == path {
+			count++
+		}
+	}
+	return count
+}
+
+func TestSetupFanOut(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	server, received := newOTLPReceiver(t)
+
+	setupForTest(t, Config{
+		Exporters: []ExporterSpec{
+			{Type: ExporterFile, Processor: ProcessorSimple},
+			{Type: ExporterHTTP, Processor: ProcessorSimple},
+		},
+		FilePath:            path,
+		Endpoint:            server.URL,
+		MetricsExporterType: ExporterNone,
+		LogsExporterType:    ExporterNone,
+	})
+
+	_, span := GetTracer("test").Start(context.Background(), "fan-out")
+	span.End()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"fan-out"`) {
+		t.Errorf("file exporter did not receive the span: %s", data)
+	}
+	if spans := received(); len(spans) != 1 || spans[0].GetName() != "fan-out" {
+		t.Errorf("OTLP exporter received %v, want the fan-out span", spans)
+	}
+}
+
+func TestSetupShutsDownCreatedExporters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	previous := otel.GetTracerProvider()
+
+	// 第二个导出器的CA文件不存在，创建失败
+	_, err := Setup(context.Background(), Config{
+		Exporters: []ExporterSpec{
+			{Type: ExporterFile, Processor: ProcessorSimple},
+			{Type: ExporterHTTP, Processor: ProcessorSimple},
+		},
+		FilePath: path,
+		Endpoint: "https://127.0.0.1:4318",
+		TLS:      TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to create http exporter") {
+		t.Fatalf("Setup() error = %v, want the http exporter error", err)
+	}
+
+	if _, statErr := os.Stat(path); statErr != nil {
+		t.Fatalf("file exporter was not created: %v", statErr)
+	}
+	if n := openFiles(t, path); n != 0 {
+		t.Errorf("file exporter left %d open files, want it shut down", n)
+	}
+	if otel.GetTracerProvider() != previous {
+		t.Error("global tracer provider changed after a failed Setup")
+	}
+}
+
+func TestSetupInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   []string
+	}{
+		{
+			name:   "unknown exporter type",
+			config: Config{ExporterType: "zipkin"},
+			want:   []string{"unsupported exporter type: zipkin"},
+		},
+		{
+			name:   "negative attribute value length limit",
+			config: Config{ExporterType: ExporterNone, AttributeValueLengthLimit: -1},
+			want:   []string{"invalid attribute value length limit: -1"},
+		},
+		{
+			name:   "endpoint without host",
+			config: Config{ExporterType: ExporterHTTP, Endpoint: "http://"},
+			want:   []string{"endpoint: invalid endpoint URL: missing host"},
+		},
+		{
+			name:   "malformed endpoint",
+			config: Config{ExporterType: ExporterHTTP, TracesEndpoint: "http://[::1"},
+			want:   []string{"traces endpoint: invalid endpoint URL"},
+		},
+		{
+			name: "all errors are reported",
+			config: Config{
+				Exporters:                 []ExporterSpec{{Type: "zipkin"}, {Type: ExporterFile, Processor: "async"}},
+				AttributeValueLengthLimit: -1,
+				Endpoint:                  "http://",
+				FileMaxBytes:              -1,
+			},
+			want: []string{
+				"unsupported exporter type: zipkin",
+				"unsupported span processor: async",
+				"invalid attribute value length limit: -1",
+				"endpoint: invalid endpoint URL: missing host",
+				"invalid file max bytes: -1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := otel.GetTracerProvider()
+
+			shutdown, err := Setup(context.Background(), tt.config)
+			if err == nil {
+				_ = shutdown(context.Background())
+				t.Fatal("Setup() succeeded, want an error")
+			}
+			if !strings.HasPrefix(err.Error(), "invalid telemetry config: ") {
+				t.Errorf("Setup() error = %v, want a validation error", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Setup() error = %v, want it to contain %q", err, want)
+				}
+			}
+			if otel.GetTracerProvider() != previous {
+				t.Error("global tracer provider changed after a failed Setup")
+			}
+		})
+	}
+}
+
+func TestSignalHeaders(t *testing.T) {
+	var mu sync.Mutex
+	headers := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		headers[r.URL.Path] = r.Header.Get("Authorization")
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	config := Config{
+		Endpoint: server.URL,
+		Headers:  map[string]string{"Authorization": "Bearer generic"},
+		Metrics:  SignalConfig{Headers: map[string]string{"Authorization": "Bearer metrics"}},
+		Logs:     SignalConfig{Headers: map[string]string{"Authorization": "Bearer logs"}},
+	}
+
+	if err := exportTestSpan(t, config, ExporterHTTP); err != nil {
+		t.Fatal(err)
+	}
+
+	metricExporter, err := createHTTPMetricExporter(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer metricExporter.Shutdown(ctx)
+	if err := metricExporter.Export(ctx, &metricdata.ResourceMetrics{}); err != nil {
+		t.Fatal(err)
+	}
+
+	logExporter, err := createHTTPLogExporter(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logExporter.Shutdown(ctx)
+	if err := logExporter.Export(ctx, []sdklog.Record{{}}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"/v1/traces":  "Bearer generic",
+		"/v1/metrics": "Bearer metrics",
+		"/v1/logs":    "Bearer logs",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(headers, want) {
+		t.Errorf("Authorization headers = %v, want %v", headers, want)
+	}
+}
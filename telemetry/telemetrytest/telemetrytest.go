@@ -0,0 +1,192 @@
+// Package telemetrytest 提供测试用的内存span记录器和GenAI断言辅助函数
+package telemetrytest
+
+import (
+	"context"
+	"testing"
+
+	"gen-ai-example/telemetry"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// Recorder 记录测试期间结束的span
+type Recorder struct {
+	exporter *tracetest.InMemoryExporter
+	provider *sdktrace.TracerProvider
+}
+
+// Install 将同步写入内存导出器的TracerProvider安装为全局provider
+//
+// 通过telemetry.GetTracer获取tracer的服务需要在Install之后创建。
+// 测试结束时会关闭provider并恢复之前的全局provider。
+func Install(tb testing.TB) *Recorder {
+	tb.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+
+	tb.Cleanup(func() {
+		if err := provider.Shutdown(context.Background()); err != nil {
+			tb.Errorf("failed to shut down tracer provider: %v", err)
+		}
+		otel.SetTracerProvider(previous)
+	})
+
+	return &Recorder{
+		exporter: exporter,
+		provider: provider,
+	}
+}
+
+// Spans 返回所有已结束的span，按结束顺序排列
+func (r *Recorder) Spans() tracetest.SpanStubs {
+	return r.exporter.GetSpans()
+}
+
+// SpansByName 返回指定名称的所有已结束span
+func (r *Recorder) SpansByName(name string) tracetest.SpanStubs {
+	var spans tracetest.SpanStubs
+	for _, span := range r.exporter.GetSpans() {
+		if span.Name == name {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// Span 返回指定名称的唯一span，数量不为1时测试失败
+func (r *Recorder) Span(tb testing.TB, name string) tracetest.SpanStub {
+	tb.Helper()
+
+	spans := r.SpansByName(name)
+	if len(spans) != 1 {
+		tb.Fatalf("expected exactly one span named %q, got %d (recorded: %v)", name, len(spans), r.names())
+	}
+	return spans[0]
+}
+
+// Reset 清空已记录的span
+func (r *Recorder) Reset() {
+	r.exporter.Reset()
+}
+
+// names 返回已记录span的名称，用于失败信息
+func (r *Recorder) names() []string {
+	spans := r.exporter.GetSpans()
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name)
+	}
+	return names
+}
+
+// Attribute 返回span上指定属性的值
+func Attribute(span tracetest.SpanStub, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+// AssertAttribute 断言span上存在指定属性且值相等
+func AssertAttribute(tb testing.TB, span tracetest.SpanStub, want attribute.KeyValue) {
+	tb.Helper()
+
+	got, ok := Attribute(span, want.Key)
+	if !ok {
+		tb.Errorf("span %q: missing attribute %q", span.Name, want.Key)
+		return
+	}
+	if got != want.Value {
+		tb.Errorf("span %q: attribute %q = %s, want %s", span.Name, want.Key, got.Emit(), want.Value.Emit())
+	}
+}
+
+// AssertNoAttribute 断言span上不存在指定属性
+func AssertNoAttribute(tb testing.TB, span tracetest.SpanStub, key attribute.Key) {
+	tb.Helper()
+
+	if got, ok := Attribute(span, key); ok {
+		tb.Errorf("span %q: unexpected attribute %q = %s", span.Name, key, got.Emit())
+	}
+}
+
+// AssertOperationName 断言span的gen_ai.operation.name
+func AssertOperationName(tb testing.TB, span tracetest.SpanStub, operation string) {
+	tb.Helper()
+	AssertAttribute(tb, span, semconv.GenAIOperationNameKey.String(operation))
+}
+
+// AssertTokenUsage 断言span的gen_ai.usage.input_tokens和gen_ai.usage.output_tokens
+func AssertTokenUsage(tb testing.TB, span tracetest.SpanStub, inputTokens, outputTokens int) {
+	tb.Helper()
+	AssertAttribute(tb, span, semconv.GenAIUsageInputTokens(inputTokens))
+	AssertAttribute(tb, span, semconv.GenAIUsageOutputTokens(outputTokens))
+}
+
+// AssertHasTokenUsage 断言span记录了正数的输入和输出token数量
+func AssertHasTokenUsage(tb testing.TB, span tracetest.SpanStub) {
+	tb.Helper()
+
+	for _, key := range []attribute.Key{semconv.GenAIUsageInputTokensKey, semconv.GenAIUsageOutputTokensKey} {
+		value, ok := Attribute(span, key)
+		if !ok {
+			tb.Errorf("span %q: missing attribute %q", span.Name, key)
+			continue
+		}
+		if value.AsInt64() <= 0 {
+			tb.Errorf("span %q: attribute %q = %d, want > 0", span.Name, key, value.AsInt64())
+		}
+	}
+}
+
+// AssertParent 断言child是parent的直接子span
+func AssertParent(tb testing.TB, parent, child tracetest.SpanStub) {
+	tb.Helper()
+
+	if child.SpanContext.TraceID() != parent.SpanContext.TraceID() {
+		tb.Errorf("span %q: trace ID %s differs from parent %q trace ID %s",
+			child.Name, child.SpanContext.TraceID(), parent.Name, parent.SpanContext.TraceID())
+		return
+	}
+	if child.Parent.SpanID() != parent.SpanContext.SpanID() {
+		tb.Errorf("span %q: parent span ID %s, want %q (%s)",
+			child.Name, child.Parent.SpanID(), parent.Name, parent.SpanContext.SpanID())
+	}
+}
+
+// AssertSameTrace 断言所有span属于同一个trace
+func AssertSameTrace(tb testing.TB, spans ...tracetest.SpanStub) {
+	tb.Helper()
+
+	if len(spans) == 0 {
+		return
+	}
+	traceID := spans[0].SpanContext.TraceID()
+	for _, span := range spans[1:] {
+		if span.SpanContext.TraceID() != traceID {
+			tb.Errorf("span %q: trace ID %s, want %s", span.Name, span.SpanContext.TraceID(), traceID)
+		}
+	}
+}
+
+// AssertConformance 断言所有span符合GenAI语义约定，每条违规报告为一个错误
+func AssertConformance(tb testing.TB, spans ...tracetest.SpanStub) {
+	tb.Helper()
+
+	for _, span := range spans {
+		for _, violation := range telemetry.CheckSpan(span.Snapshot()) {
+			tb.Errorf("%s", violation)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package telemetry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSConfig 定义OTLP导出器的TLS配置
+//
+// 是否启用TLS由endpoint的scheme决定：https启用TLS，http使用明文连接；
+// 没有scheme的gRPC端点默认启用TLS，设置Insecure后使用明文连接。
+type TLSConfig struct {
+	// Insecure 没有scheme的gRPC端点使用明文连接，对应OTEL_EXPORTER_OTLP_INSECURE
+	Insecure bool
+	// CAFile 用于校验服务端证书的CA证书文件（PEM格式）
+	CAFile string
+	// CertFile 客户端证书文件（PEM格式），用于mTLS
+	CertFile string
+	// KeyFile 客户端私钥文件（PEM格式），用于mTLS
+	KeyFile string
+	// InsecureSkipVerify 跳过服务端证书校验，仅用于测试环境
+	InsecureSkipVerify bool
+}
+
+// merge 返回用override中的非空字段覆盖后的配置
+//
+// 客户端证书和私钥作为一组覆盖，避免与通用配置中的另一半混用。
+func (c TLSConfig) merge(override TLSConfig) TLSConfig {
+	if override.CAFile != "" {
+		c.CAFile = override.CAFile
+	}
+	if override.CertFile != "" || override.KeyFile != "" {
+		c.CertFile = override.CertFile
+		c.KeyFile = override.KeyFile
+	}
+	if override.Insecure {
+		c.Insecure = true
+	}
+	if override.InsecureSkipVerify {
+		c.InsecureSkipVerify = true
+	}
+	return c
+}
+
+// build 根据配置创建tls.Config
+func (c TLSConfig) build() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CAFile != "" {
+		caPEM, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no valid certificates found in CA file: %s", c.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		if c.CertFile == "" || c.KeyFile == "" {
+			return nil, fmt.Errorf("client certificate and key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,220 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// otlpRequest TLS测试服务器收到的一次OTLP请求
+type otlpRequest struct {
+	path   string
+	header http.Header
+}
+
+// newOTLPTLSServer 启动记录请求的TLS服务器，clientCA非空时要求客户端证书
+func newOTLPTLSServer(t *testing.T, clientCA *x509.Certificate) (*httptest.Server, func() []otlpRequest) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var requests []otlpRequest
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, otlpRequest{path: r.URL.Path, header: r.Header.Clone()})
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	if clientCA != nil {
+		pool := x509.NewCertPool()
+		pool.AddCert(clientCA)
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return server, func() []otlpRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]otlpRequest(nil), requests...)
+	}
+}
+
+// writeServerCA 将测试服务器的证书写入PEM文件，返回文件路径
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writeClientCert 生成自签名的客户端证书，返回证书、证书文件和私钥文件路径
+func writeClientCert(t *testing.T) (*x509.Certificate, string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "otlp-client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return cert, certFile, keyFile
+}
+
+// exportTestSpan 使用config创建导出器并导出一个span
+func exportTestSpan(t *testing.T, config Config, exporterType ExporterType) error {
+	t.Helper()
+
+	ctx := context.Background()
+	exporter, err := createSpanExporter(ctx, config, exporterType)
+	if err != nil {
+		t.Fatalf("createSpanExporter: %v", err)
+	}
+	defer exporter.Shutdown(ctx)
+
+	spans := tracetest.SpanStubs{{Name: "tls-test"}}.Snapshots()
+	return exporter.ExportSpans(ctx, spans)
+}
+
+func TestHTTPExporterTLS(t *testing.T) {
+	server, requests := newOTLPTLSServer(t, nil)
+	caFile := writeServerCA(t, server)
+
+	tests := []struct {
+		name    string
+		tls     TLSConfig
+		wantErr bool
+	}{
+		{name: "custom CA", tls: TLSConfig{CAFile: caFile}},
+		{name: "skip verify", tls: TLSConfig{InsecureSkipVerify: true}},
+		{name: "untrusted server", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(requests())
+			err := exportTestSpan(t, Config{
+				Endpoint: server.URL + "/otlp",
+				Headers:  map[string]string{"Authorization": "Bearer test-token"},
+				TLS:      tt.tls,
+				Timeout:  5 * time.Second,
+			}, ExporterHTTP)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected export to an untrusted server to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("export failed: %v", err)
+			}
+
+			got := requests()[before:]
+			if len(got) != 1 {
+				t.Fatalf("server received %d requests, want 1", len(got))
+			}
+			if got[0].path != "/otlp/v1/traces" {
+				t.Errorf("path = %q, want /otlp/v1/traces", got[0].path)
+			}
+			if auth := got[0].header.Get("Authorization"); auth != "Bearer test-token" {
+				t.Errorf("Authorization = %q, want Bearer test-token", auth)
+			}
+		})
+	}
+}
+
+func TestHTTPExporterClientCertificate(t *testing.T) {
+	clientCert, certFile, keyFile := writeClientCert(t)
+	server, requests := newOTLPTLSServer(t, clientCert)
+	caFile := writeServerCA(t, server)
+
+	config := Config{
+		Endpoint: server.URL,
+		TLS:      TLSConfig{CAFile: caFile},
+		Timeout:  5 * time.Second,
+	}
+	if err := exportTestSpan(t, config, ExporterHTTP); err == nil {
+		t.Fatal("expected export without a client certificate to fail")
+	}
+
+	config.TLS.CertFile = certFile
+	config.TLS.KeyFile = keyFile
+	if err := exportTestSpan(t, config, ExporterHTTP); err != nil {
+		t.Fatalf("export with client certificate failed: %v", err)
+	}
+	if got := requests(); len(got) != 1 {
+		t.Fatalf("server received %d requests, want 1", len(got))
+	}
+}
+
+func TestTLSConfigBuildErrors(t *testing.T) {
+	dir := t.TempDir()
+	invalidCA := filepath.Join(dir, "invalid.pem")
+	if err := os.WriteFile(invalidCA, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		tls  TLSConfig
+	}{
+		{name: "missing CA file", tls: TLSConfig{CAFile: filepath.Join(dir, "missing.pem")}},
+		{name: "invalid CA file", tls: TLSConfig{CAFile: invalidCA}},
+		{name: "certificate without key", tls: TLSConfig{CertFile: invalidCA}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.tls.build(); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Validate 检查配置是否合法，返回所有发现的问题
+func (c Config) Validate() error {
+	var errs []error
+
+	for _, spec := range c.exporterSpecs() {
+		switch spec.Type {
+		case ExporterConsole, ExporterHTTP, ExporterGRPC, ExporterAuto, ExporterFile, ExporterNone, "":
+		default:
+			errs = append(errs, fmt.Errorf("unsupported exporter type: %s", spec.Type))
+		}
+		switch spec.Processor {
+		case ProcessorBatch, ProcessorSimple, "":
+		default:
+			errs = append(errs, fmt.Errorf("unsupported span processor: %s", spec.Processor))
+		}
+	}
+
+	switch c.MetricsExporterType {
+	case ExporterConsole, ExporterHTTP, ExporterGRPC, ExporterAuto, ExporterFile, ExporterNone, ExporterPrometheus, "":
+	default:
+		errs = append(errs, fmt.Errorf("unsupported metrics exporter type: %s", c.MetricsExporterType))
+	}
+
+	switch c.LogsExporterType {
+	case ExporterConsole, ExporterHTTP, ExporterGRPC, ExporterAuto, ExporterFile, ExporterNone, "":
+	default:
+		errs = append(errs, fmt.Errorf("unsupported logs exporter type: %s", c.LogsExporterType))
+	}
+
+	switch c.SemconvStability {
+	case SemconvStabilityLatest, SemconvStabilityDup, "":
+	default:
+		errs = append(errs, fmt.Errorf("unsupported semconv stability: %s", c.SemconvStability))
+	}
+
+	switch c.ContentCapture {
+	case ContentCaptureOff, ContentCaptureMetadata, ContentCaptureFull, "":
+	default:
+		errs = append(errs, fmt.Errorf("unsupported content capture mode: %s", c.ContentCapture))
+	}
+
+	endpoints := []struct {
+		name  string
+		value string
+	}{
+		{"endpoint", c.Endpoint},
+		{"traces endpoint", c.TracesEndpoint},
+		{"metrics endpoint", c.MetricsEndpoint},
+		{"logs endpoint", c.LogsEndpoint},
+	}
+	for _, endpoint := range endpoints {
+		if endpoint.value == "" {
+			continue
+		}
+		if _, err := parseOTLPEndpoint(endpoint.value, ""); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", endpoint.name, err))
+		}
+	}
+
+	if c.FileMaxBytes < 0 {
+		errs = append(errs, fmt.Errorf("invalid file max bytes: %d", c.FileMaxBytes))
+	}
+	if c.AttributeValueLengthLimit < 0 {
+		errs = append(errs, fmt.Errorf("invalid attribute value length limit: %d", c.AttributeValueLengthLimit))
+	}
+
+	signals := []struct {
+		name   string
+		config SignalConfig
+	}{
+		{"", SignalConfig{TLS: c.TLS, Timeout: c.Timeout, Compression: c.Compression}},
+		{"traces ", c.Traces},
+		{"metrics ", c.Metrics},
+		{"logs ", c.Logs},
+	}
+	for _, signal := range signals {
+		errs = append(errs, signal.config.validate(signal.name)...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate 检查OTLP设置，错误信息以prefix开头
+func (c SignalConfig) validate(prefix string) []error {
+	var errs []error
+	switch c.Compression {
+	case "gzip", "none", "":
+	default:
+		errs = append(errs, fmt.Errorf("unsupported %scompression: %s", prefix, c.Compression))
+	}
+	if c.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("invalid %stimeout: %v", prefix, c.Timeout))
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		errs = append(errs, fmt.Errorf("%sclient certificate and key must be set together", prefix))
+	}
+	return errs
+}